@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"crypto/sha1"
+	"encoding/gob"
+	"fmt"
+	"go/ast"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/wookesh/gohist/objects"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const cacheVersion = 11
+
+type cacheFile struct {
+	Version int
+	Tips    []string
+	History *objects.Snapshot
+}
+
+type cachedHistory struct {
+	history   *objects.History
+	processed map[string]bool
+}
+
+// cachePath returns cache file of history analyzed with opts down to resolved end, which is derived from -since when
+// -end is not set.
+func cachePath(cacheDir, repoPath, end string, opts Options) string {
+	key := fmt.Sprintf("%s\x00%s\x00%v\x00%v\x00%s\x00%v\x00%v\x00%v", repoPath, end, opts.WithTests, opts.Simple,
+		opts.Filter, opts.SkipGenerated, opts.WithDocs, opts.Alpha)
+	return filepath.Join(cacheDir, fmt.Sprintf("%x.gob", sha1.Sum([]byte(key))))
+}
+
+// loadCache restores history saved by saveCache, cached tips have to be part of currently analyzed graph.
+func loadCache(cachePath string, commits map[string]*object.Commit, end string, graph map[string]*Node) (*cachedHistory, error) {
+	f, err := os.Open(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cache cacheFile
+	if err := gob.NewDecoder(f).Decode(&cache); err != nil {
+		return nil, err
+	}
+	if cache.Version != cacheVersion {
+		return nil, fmt.Errorf("cache version %d, expected %d", cache.Version, cacheVersion)
+	}
+	for _, tip := range cache.Tips {
+		if _, ok := graph[tip]; !ok {
+			return nil, fmt.Errorf("cached commit %s is not an ancestor of analyzed commits", tip)
+		}
+	}
+
+	history, err := objects.Restore(cache.History, newCommitResolver(commits))
+	if err != nil {
+		return nil, err
+	}
+	_, _, cachedGraph := createGraph(commits, cache.Tips, end)
+	processed := make(map[string]bool, len(cachedGraph))
+	for sha := range cachedGraph {
+		processed[sha] = true
+	}
+	return &cachedHistory{history: history, processed: processed}, nil
+}
+
+func saveCache(cachePath string, tips []string, history *objects.History) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(cachePath), filepath.Base(cachePath))
+	if err != nil {
+		return err
+	}
+	err = gob.NewEncoder(f).Encode(&cacheFile{Version: cacheVersion, Tips: tips, History: history.Snapshot()})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), cachePath)
+}
+
+type commitResolver struct {
+	commits map[string]*object.Commit
+	files   map[string]*Declarations
+	bodies  map[string][]byte
+}
+
+func newCommitResolver(commits map[string]*object.Commit) *commitResolver {
+	return &commitResolver{
+		commits: commits,
+		files:   make(map[string]*Declarations),
+		bodies:  make(map[string][]byte),
+	}
+}
+
+func (r *commitResolver) Commit(sha string) (*object.Commit, error) {
+	commit, ok := r.commits[sha]
+	if !ok {
+		return nil, fmt.Errorf("commit %s not found", sha)
+	}
+	return commit, nil
+}
+
+func (r *commitResolver) Decl(id, sha, file string) (ast.Decl, *objects.SplitName, []byte, error) {
+	key := sha + ":" + file
+	decls, ok := r.files[key]
+	if !ok {
+		commit, err := r.Commit(sha)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		f, err := commit.File(file)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		decls, err = GetDeclarationsWithDocs(contents, file, path.Dir(file))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		r.files[key] = decls
+		r.bodies[key] = []byte(contents)
+	}
+	if decl, ok := decls.Functions[id]; ok {
+		return decl, nil, r.bodies[key], nil
+	}
+	if decl, ok := decls.Types[id]; ok {
+		return decl, nil, r.bodies[key], nil
+	}
+	if decl, ok := decls.Variables[id]; ok {
+		return decl, decls.Splits[id], r.bodies[key], nil
+	}
+	return nil, nil, nil, fmt.Errorf("%s not found in %s at %s", id, file, sha)
+}
@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wookesh/gohist/internal/gittest"
+	"github.com/wookesh/gohist/objects"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestCachePath(t *testing.T) {
+	base := cachePath("cache", "repo", "a", Options{})
+	tests := []struct {
+		name string
+		end  string
+		opts Options
+		same bool
+	}{
+		{"same", "a", Options{}, true},
+		{"other end", "b", Options{}, false},
+		{"since resolved to the same end", "a", Options{Since: time.Now()}, true},
+		{"tests", "a", Options{WithTests: true}, false},
+		{"alpha", "a", Options{Alpha: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := cachePath("cache", "repo", tt.end, tt.opts) == base; same != tt.same {
+				t.Errorf("cachePath() equal to base = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+// commitFile writes main.go with given source to worktree of repository in dir and commits it as commit i.
+func commitFile(t *testing.T, repo *git.Repository, dir string, i int, src string) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\n"+src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("main.go"); err != nil {
+		t.Fatal(err)
+	}
+	signature := &object.Signature{Name: "a", Email: "a@example.com", When: gittest.Start.AddDate(0, 0, i)}
+	if _, err := worktree.Commit(fmt.Sprint("commit ", i), &git.CommitOptions{Author: signature, Committer: signature}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateHistoryCache(t *testing.T) {
+	dir, cacheDir := t.TempDir(), t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []string{
+		"func F() { f() }\n\nfunc Old(a, b int) int { return a*b + a - b + a*a - b*b }\n",
+		"func F() { f(); f() }\n\nfunc New(a, b int) int { return a*b + a - b + a*a - b*b }\n",
+		"func F() { g() }\n\nfunc New(a, b int) int { return a*b + a - b + a*a - b*b }\n\nfunc G() {}\n",
+	}
+	opts := Options{Start: "HEAD", CacheDir: cacheDir}
+	for i, src := range versions[:2] {
+		commitFile(t, repo, dir, i, src)
+	}
+	if _, err := CreateHistory(dir, opts); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, dir, 2, versions[2])
+
+	head, err := resolveRevision(repo, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits := make(map[string]*object.Commit)
+	iter, err := repo.CommitObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter.ForEach(func(commit *object.Commit) error {
+		commits[commit.Hash.String()] = commit
+		return nil
+	})
+	_, _, graph := createGraph(commits, []string{head}, "")
+	cached, err := loadCache(cachePath(cacheDir, dir, "", opts), commits, "", graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached.processed) != 2 || cached.processed[head] {
+		t.Errorf("cache processed %d commits including head %v, want 2 older ones", len(cached.processed),
+			cached.processed[head])
+	}
+
+	updated, err := CreateHistory(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := CreateHistory(dir, Options{Start: "HEAD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := updated.Snapshot(), fresh.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("history updated from cache = %+v, want %+v", got, want)
+	}
+	if fh := updated.Data["New"]; fh == nil || fh.Origin == nil || fh.Origin.ID != "Old" {
+		t.Errorf("history updated from cache lost rename of Old to New: %v", fh)
+	}
+	if fh := updated.Data["F"]; fh == nil || fh.VersionsCount() != 3 {
+		t.Errorf("F updated from cache = %v, want 3 versions", fh)
+	}
+}
+
+func TestCreateHistoryCacheDocs(t *testing.T) {
+	dir, cacheDir := t.TempDir(), t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []string{
+		"// Old multiplies.\nfunc Old(a, b int) int { return a*b + a - b + a*a - b*b }\n",
+		"// Old multiplies.\nfunc New(a, b int) int { return a*b + a - b + a*a - b*b }\n",
+		"// New multiplies.\nfunc New(a, b int) int { return a*b + a - b + a*a - b*b }\n",
+	}
+	opts := Options{Start: "HEAD", CacheDir: cacheDir, WithDocs: true}
+	for i, src := range versions[:2] {
+		commitFile(t, repo, dir, i, src)
+	}
+	if _, err := CreateHistory(dir, opts); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, dir, 2, versions[2])
+
+	updated, err := CreateHistory(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := CreateHistory(dir, Options{Start: "HEAD", WithDocs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := updated.Snapshot(), fresh.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("history updated from cache = %+v, want %+v", got, want)
+	}
+	for _, id := range []string{"Old", "New"} {
+		for sha, elem := range fresh.Data[id].Elements {
+			restored := updated.Data[id].Elements[sha]
+			if elem.Decl == nil {
+				continue
+			}
+			if got, want := objects.DocText(restored.Decl), objects.DocText(elem.Decl); got != want {
+				t.Errorf("doc of %s at %s updated from cache = %q, want %q", id, sha, got, want)
+			}
+		}
+	}
+	var docs []string
+	for _, version := range updated.Data["New"].SortedDocs() {
+		docs = append(docs, version.Text)
+	}
+	if want := []string{"// Old multiplies.", "// Old multiplies.", "// New multiplies."}; !reflect.DeepEqual(docs, want) {
+		t.Errorf("docs of New updated from cache = %q, want %q", docs, want)
+	}
+}
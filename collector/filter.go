@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// DefaultExclude is used when no exclude patterns are configured.
+var DefaultExclude = []string{"**/vendor/**", "**/Godeps/**"}
+
+// Filter decides which files are analyzed. Patterns prefixed with "re:" are regular expressions, other patterns
+// are globs where "**" matches any number of directories and trailing "/..." matches directory with its content.
+type Filter struct {
+	include, exclude []*regexp.Regexp
+	patterns         string
+}
+
+type FilterConfig struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+func LoadFilterConfig(path string) (*FilterConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &FilterConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+func NewFilter(include, exclude []string) (*Filter, error) {
+	f := &Filter{patterns: fmt.Sprintf("%q %q", include, exclude)}
+	for _, pattern := range include {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		f.include = append(f.include, re)
+	}
+	for _, pattern := range exclude {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		f.exclude = append(f.exclude, re)
+	}
+	return f, nil
+}
+
+// Match reports whether file should be analyzed, excludes take precedence over includes.
+func (f *Filter) Match(path string) bool {
+	if f == nil {
+		return true
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(path) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.patterns
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "re:") {
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, "re:"))
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %v", pattern, err)
+		}
+		return re, nil
+	}
+	if strings.HasSuffix(pattern, "/...") {
+		pattern = strings.TrimSuffix(pattern, "...") + "**"
+	}
+	re, err := regexp.Compile("^" + globToRegexp(pattern) + "$")
+	if err != nil {
+		return nil, fmt.Errorf("pattern %s: %v", pattern, err)
+	}
+	return re, nil
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString("(.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
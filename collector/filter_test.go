@@ -0,0 +1,46 @@
+package collector
+
+import "testing"
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name             string
+		include, exclude []string
+		path             string
+		want             bool
+	}{
+		{"no patterns", nil, nil, "a/b.go", true},
+		{"default vendor", nil, DefaultExclude, "vendor/x/y.go", false},
+		{"default nested vendor", nil, DefaultExclude, "a/vendor/y.go", false},
+		{"default vendor-like name", nil, DefaultExclude, "vendoring/y.go", true},
+		{"protobuf", nil, []string{"**/*.pb.go"}, "api/v1/service.pb.go", false},
+		{"protobuf in root", nil, []string{"**/*.pb.go"}, "service.pb.go", false},
+		{"protobuf other", nil, []string{"**/*.pb.go"}, "api/v1/service.go", true},
+		{"mocks", nil, []string{"**/mock_*.go"}, "a/b/mock_store.go", false},
+		{"star does not cross directories", nil, []string{"*.go"}, "a/b.go", true},
+		{"include directory", []string{"internal/..."}, nil, "internal/a/b.go", true},
+		{"include directory other", []string{"internal/..."}, nil, "cmd/main.go", false},
+		{"include and exclude", []string{"internal/..."}, []string{"**/*_gen.go"}, "internal/x_gen.go", false},
+		{"regexp", nil, []string{`re:(^|/)zz_generated\.`}, "pkg/zz_generated.deepcopy.go", false},
+		{"character class", nil, []string{"a/[bc].go"}, "a/c.go", false},
+		{"negated character class", nil, []string{"a/[!bc].go"}, "a/c.go", true},
+		{"question mark", nil, []string{"a/?.go"}, "a/xy.go", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.Match(tt.path); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterInvalidPattern(t *testing.T) {
+	if _, err := NewFilter(nil, []string{"re:("}); err == nil {
+		t.Error("NewFilter() should fail on invalid regexp")
+	}
+}
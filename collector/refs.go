@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// dateLayouts are accepted by ParseDate.
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+
+func ParseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD, YYYY-MM-DD HH:MM:SS or RFC3339", s)
+}
+
+var shortHash = regexp.MustCompile(`^[0-9a-f]{4,39}$`)
+
+// ResolveRevision opens repository and resolves revision in it.
+func ResolveRevision(repoPath, rev string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return resolveRevision(repo, rev)
+}
+
+// resolveRevision returns commit hash of revision: hash or its unique prefix, branch, remote branch, tag
+// or relative ref like HEAD~5.
+func resolveRevision(repo *git.Repository, rev string) (string, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err == nil {
+		return hash.String(), nil
+	}
+	if !shortHash.MatchString(rev) {
+		return "", fmt.Errorf("revision %s: %v", rev, err)
+	}
+	commits, iterErr := repo.CommitObjects()
+	if iterErr != nil {
+		return "", iterErr
+	}
+	var found []string
+	commits.ForEach(func(commit *object.Commit) error {
+		if strings.HasPrefix(commit.Hash.String(), rev) {
+			found = append(found, commit.Hash.String())
+		}
+		return nil
+	})
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("revision %s: %v", rev, err)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("revision %s is ambiguous", rev)
+	}
+}
+
+// since returns the oldest commit on first-parent chain of head which was committed at or after date.
+func since(commits map[string]*object.Commit, head string, date time.Time) string {
+	commit := commits[head]
+	for commit != nil && len(commit.ParentHashes) > 0 {
+		parent, ok := commits[commit.ParentHashes[0].String()]
+		if !ok || parent.Committer.When.Before(date) {
+			break
+		}
+		commit = parent
+	}
+	return commit.Hash.String()
+}
@@ -0,0 +1,180 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/wookesh/gohist/internal/gittest"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// testRepo creates in-memory repository with linear history of n commits made a day apart, it returns their hashes
+// from the oldest.
+func testRepo(t *testing.T, n int) (*git.Repository, []string) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hashes []string
+	for i := 0; i < n; i++ {
+		f, err := worktree.Filesystem.Create("main.go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(f, "package main\n\nconst version = %d\n", i)
+		f.Close()
+		if _, err := worktree.Add("main.go"); err != nil {
+			t.Fatal(err)
+		}
+		signature := &object.Signature{Name: "a", Email: "a@example.com", When: gittest.Start.AddDate(0, 0, i)}
+		hash, err := worktree.Commit(fmt.Sprint("commit ", i), &git.CommitOptions{Author: signature, Committer: signature})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash.String())
+	}
+	return repo, hashes
+}
+
+func TestResolveRevision(t *testing.T) {
+	repo, hashes := testRepo(t, 3)
+	if _, err := repo.CreateTag("v1", plumbing.NewHash(hashes[1]), nil); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		rev     string
+		want    string
+		wantErr bool
+	}{
+		{"HEAD", hashes[2], false},
+		{"master", hashes[2], false},
+		{"HEAD~2", hashes[0], false},
+		{"v1", hashes[1], false},
+		{hashes[0], hashes[0], false},
+		{hashes[1][:8], hashes[1], false},
+		{"unknown", "", true},
+		{"0000000", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rev, func(t *testing.T) {
+			got, err := resolveRevision(repo, tt.rev)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveRevision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveRevision() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// testCommits returns commits with given parents, commit i is made i days after gittest.Start.
+func testCommits(parents ...[]int) (commits map[string]*object.Commit, hashes []string) {
+	commits = make(map[string]*object.Commit)
+	var list []*object.Commit
+	for i, ps := range parents {
+		var commitParents []*object.Commit
+		for _, p := range ps {
+			commitParents = append(commitParents, list[p])
+		}
+		commit := gittest.Commit(i, "a@example.com", commitParents...)
+		commits[commit.Hash.String()] = commit
+		list = append(list, commit)
+		hashes = append(hashes, commit.Hash.String())
+	}
+	return commits, hashes
+}
+
+func TestSince(t *testing.T) {
+	// 0 <- 1 <- 2 <- 4, 1 <- 3 <- 4
+	commits, hashes := testCommits(nil, []int{0}, []int{1}, []int{1}, []int{2, 3})
+	tests := []struct {
+		name string
+		date time.Time
+		want string
+	}{
+		{"before root", gittest.Start.AddDate(0, 0, -1), hashes[0]},
+		{"at root", gittest.Start, hashes[0]},
+		{"between", gittest.Start.Add(36 * time.Hour), hashes[2]},
+		{"first parent only", gittest.Start.AddDate(0, 0, 2), hashes[2]},
+		{"after head", gittest.Start.AddDate(0, 0, 10), hashes[4]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := since(commits, hashes[4], tt.date); got != tt.want {
+				t.Errorf("since() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateGraph(t *testing.T) {
+	// 0 <- 1 <- 2 (main), 1 <- 3 (feature), 0 <- 4 (unrelated to heads)
+	commits, hashes := testCommits(nil, []int{0}, []int{1}, []int{1}, []int{0})
+	tests := []struct {
+		name  string
+		heads []int
+		end   int
+		tips  []int
+		root  int
+		graph []int
+	}{
+		{"single head", []int{2}, -1, []int{2}, 0, []int{0, 1, 2}},
+		{"two heads", []int{2, 3}, -1, []int{2, 3}, 0, []int{0, 1, 2, 3}},
+		{"two heads with end", []int{2, 3}, 1, []int{2, 3}, 1, []int{1, 2, 3}},
+		{"head before end", []int{2, 3}, 2, []int{2}, 2, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var heads []string
+			for _, i := range tt.heads {
+				heads = append(heads, hashes[i])
+			}
+			end := ""
+			if tt.end >= 0 {
+				end = hashes[tt.end]
+			}
+			tips, root, graph := createGraph(commits, heads, end)
+			var gotTips []string
+			for _, tip := range tips {
+				gotTips = append(gotTips, tip.SHA())
+			}
+			if fmt.Sprint(gotTips) != fmt.Sprint(indexes(hashes, tt.tips)) {
+				t.Errorf("tips = %v, want %v", gotTips, indexes(hashes, tt.tips))
+			}
+			if root.SHA() != hashes[tt.root] {
+				t.Errorf("root = %s, want %s", root.SHA(), hashes[tt.root])
+			}
+			var gotGraph []string
+			for sha, node := range graph {
+				gotGraph = append(gotGraph, sha)
+				for _, parent := range node.Parents {
+					if _, ok := graph[parent.SHA()]; !ok {
+						t.Errorf("parent %s of %s is not in graph", parent.SHA(), sha)
+					}
+				}
+			}
+			sort.Strings(gotGraph)
+			if fmt.Sprint(gotGraph) != fmt.Sprint(indexes(hashes, tt.graph)) {
+				t.Errorf("graph = %v, want %v", gotGraph, indexes(hashes, tt.graph))
+			}
+		})
+	}
+}
+
+func indexes(hashes []string, is []int) (result []string) {
+	for _, i := range is {
+		result = append(result, hashes[i])
+	}
+	return result
+}
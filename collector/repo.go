@@ -7,20 +7,50 @@ import (
 	"go/token"
 	"io/ioutil"
 	"path"
-	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/wookesh/gohist/objects"
 	"github.com/wookesh/semaphore"
 	"gopkg.in/src-d/go-git.v4"
-	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
-func CreateHistory(repoPath string, start, end string, withTests bool, simple bool) (*objects.History, error) {
+// Options configure which commits and files are analyzed. Start, End and Branches accept any revision
+// understood by go-git, Since limits history to commits made after given date when End is empty.
+type Options struct {
+	Start     string
+	End       string
+	Branches  []string
+	Since     time.Time
+	WithTests bool
+	Simple    bool
+	CacheDir  string
+	Filter    *Filter
+	// SkipGenerated drops files with generated code header instead of tracking them as generated
+	SkipGenerated bool
+	// WithDocs parses comments and tracks versions of function doc comments
+	WithDocs bool
+	// Alpha treats consistent renames of local variables and parameters as no change, Simple takes precedence
+	Alpha bool
+}
+
+func (opts Options) equivalence() objects.Equivalence {
+	switch {
+	case opts.Simple:
+		return objects.EquivalenceText
+	case opts.Alpha:
+		return objects.EquivalenceAlpha
+	default:
+		return objects.EquivalenceAST
+	}
+}
+
+func CreateHistory(repoPath string, opts Options) (*objects.History, error) {
 	logrus.Debugln("CreateHistory:", repoPath)
 	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
@@ -39,25 +69,44 @@ func CreateHistory(repoPath string, start, end string, withTests bool, simple bo
 	total := 0
 	commitIterator.ForEach(func(commit *object.Commit) error {
 		if commit == nil {
-			panic("commit is nil")
 			return fmt.Errorf("commit is nil")
 		}
 		commitsData[commit.Hash.String()] = commit
 		return nil
 	})
 
-	_, err = repo.CommitObject(plumbing.NewHash(start))
-	if err != nil {
-		ref, err := repo.Reference(plumbing.ReferenceName("refs/heads/"+start), false)
+	var heads []objects.Head
+	for _, rev := range append([]string{opts.Start}, opts.Branches...) {
+		sha, err := resolveRevision(repo, rev)
 		if err != nil {
 			return nil, err
 		}
-		start = ref.Hash().String()
+		heads = append(heads, objects.Head{Name: rev, SHA: sha})
+	}
+	var end string
+	if opts.End != "" {
+		if end, err = resolveRevision(repo, opts.End); err != nil {
+			return nil, err
+		}
+	} else if !opts.Since.IsZero() {
+		end = since(commitsData, heads[0].SHA, opts.Since)
 	}
 
 	history := objects.NewHistory()
+	processed := make(map[string]bool)
 
-	last, first, graph := createGraph(commitsData, start, end)
+	tips, root, graph := createGraph(commitsData, headSHAs(heads), end)
+	var cache string
+	if opts.CacheDir != "" {
+		cache = cachePath(opts.CacheDir, repoPath, end, opts)
+		cached, err := loadCache(cache, commitsData, end, graph)
+		if err != nil {
+			logrus.Warningln("CreateHistory:", "cache not used:", err)
+		} else {
+			history = cached.history
+			processed = cached.processed
+		}
+	}
 	parentLocks := make(map[string]semaphore.Semaphore)
 	for sha, node := range graph {
 		total++
@@ -70,8 +119,9 @@ func CreateHistory(repoPath string, start, end string, withTests bool, simple bo
 		}
 	}
 	done := int32(0)
+	finished := int32(0)
 	queue := make(chan *Node, 1)
-	queue <- first
+	queue <- root
 	var wg sync.WaitGroup
 	queued := make(map[string]bool)
 	var m sync.Mutex
@@ -90,57 +140,11 @@ func CreateHistory(repoPath string, start, end string, withTests bool, simple bo
 				parentLocks[node.SHA()].P()
 			}
 
-			files, err := node.Commit.Files()
-			if err != nil {
-				logrus.Fatalln(err)
-			}
-			var count int32
-			var changed int32
-			err = files.ForEach(func(f *object.File) error {
-				if strings.Contains(f.Name, "vendor") || strings.Contains(f.Name, "Godeps") {
-					// skip
-					return nil
-				}
-				if !strings.HasSuffix(f.Name, ".go") || (strings.HasSuffix(f.Name, "_test.go") && !withTests) {
-					return nil
-				}
-				logrus.Debugln("CreateHistory:", "\t", f.Name)
-				rd, err := f.Blob.Reader()
-				if err != nil {
-					return err
-				}
-				body, err := ioutil.ReadAll(rd)
-				if err != nil {
-					logrus.Error("file.ForEach:", err)
-					return err
-				}
-				functions, err := GetFunctions(string(body), f.Name, path.Dir(f.Name))
-				if err != nil {
-					logrus.Warningln("CreateHistory:", "parse error:", err, f.Name)
-					return nil
-				}
-				for funcID, funcDeclaration := range functions {
-					added := history.Get(funcID).AddElement(funcDeclaration, node.Commit, body, simple)
-					if added {
-						atomic.AddInt32(&changed, 1)
-					}
-					atomic.AddInt32(&count, 1)
-				}
-				return nil
-			})
-			if err != nil {
-				logrus.Fatalln(err)
-			}
-
-			if changed > history.MaxChanged {
-				history.MaxChanged = changed
+			if !processed[node.SHA()] {
+				analyzeCommit(history, node.Commit, opts)
 			}
 
-			atomic.AddInt32(&history.CommitsAnalyzed, 1)
-			history.Mark(node.Commit.Author.When, int(count))
-			history.CheckForDeleted(node.Commit)
-
-			if node == last {
+			if atomic.AddInt32(&finished, 1) == int32(total) {
 				close(queue)
 			} else {
 				for _, child := range node.Children {
@@ -161,10 +165,118 @@ func CreateHistory(repoPath string, start, end string, withTests bool, simple bo
 	for _, f := range history.Data {
 		f.PostProcess()
 	}
+	for _, t := range history.Types {
+		t.PostProcess()
+	}
+	for _, v := range history.Variables {
+		v.PostProcess()
+	}
+	history.LinkTests()
+	for sha, node := range graph {
+		history.Commits[sha] = node.Commit
+	}
+
+	history.Equivalence = opts.equivalence()
+	for _, head := range heads {
+		if _, ok := graph[head.SHA]; !ok {
+			logrus.Warningln("CreateHistory:", head.Name, "is not a descendant of", end)
+			continue
+		}
+		history.Heads = append(history.Heads, head)
+	}
+
+	if cache != "" {
+		var shas []string
+		for _, tip := range tips {
+			shas = append(shas, tip.SHA())
+		}
+		if err := saveCache(cache, shas, history); err != nil {
+			logrus.Warningln("CreateHistory:", "cache not saved:", err)
+		}
+	}
 
 	return history, nil
 }
 
+func analyzeCommit(history *objects.History, commit *object.Commit, opts Options) {
+	files, err := commit.Files()
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	var count int32
+	var changed int32
+	var generated int32
+	err = files.ForEach(func(f *object.File) error {
+		if !strings.HasSuffix(f.Name, ".go") || (strings.HasSuffix(f.Name, "_test.go") && !opts.WithTests) {
+			return nil
+		}
+		if !opts.Filter.Match(f.Name) {
+			return nil
+		}
+		logrus.Debugln("CreateHistory:", "\t", f.Name)
+		rd, err := f.Blob.Reader()
+		if err != nil {
+			return err
+		}
+		body, err := ioutil.ReadAll(rd)
+		if err != nil {
+			logrus.Error("file.ForEach:", err)
+			return err
+		}
+		getDecls := GetDeclarations
+		if opts.WithDocs {
+			getDecls = GetDeclarationsWithDocs
+		}
+		decls, err := getDecls(string(body), f.Name, path.Dir(f.Name))
+		if err != nil {
+			logrus.Warningln("CreateHistory:", "parse error:", err, f.Name)
+			history.AddError(commit, f.Name, err)
+			return nil
+		}
+		if decls.Generated && opts.SkipGenerated {
+			return nil
+		}
+		for _, err := range decls.Errors {
+			logrus.Warningln("CreateHistory:", "skipped declaration:", err, commit.Hash)
+			history.AddError(commit, f.Name, err)
+		}
+		for funcID, funcDeclaration := range decls.Functions {
+			added := history.Get(funcID).AddElement(funcDeclaration, commit, f.Name, body, opts.equivalence(), decls.Generated)
+			if opts.WithDocs {
+				history.Get(funcID).AddDoc(objects.DocText(funcDeclaration), commit)
+			}
+			if decls.Generated {
+				atomic.AddInt32(&generated, 1)
+				continue
+			}
+			if added {
+				atomic.AddInt32(&changed, 1)
+			}
+			atomic.AddInt32(&count, 1)
+		}
+		for typeID, typeDeclaration := range decls.Types {
+			history.GetType(typeID).AddElement(typeDeclaration, commit, f.Name, body, opts.equivalence(), decls.Generated)
+		}
+		for varID, varDeclaration := range decls.Variables {
+			history.GetVariable(varID).AddVariable(varDeclaration, decls.Splits[varID], commit, f.Name, body,
+				opts.equivalence(), decls.Generated)
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+
+	if changed > history.MaxChanged {
+		history.MaxChanged = changed
+	}
+
+	atomic.AddInt32(&history.CommitsAnalyzed, 1)
+	history.Mark(commit.Author.When, int(count), int(generated))
+	history.CheckForDeleted(commit)
+	history.DetectRenames(commit)
+}
+
 type Node struct {
 	Commit   *object.Commit
 	Children []*Node
@@ -175,7 +287,16 @@ func (n *Node) SHA() string {
 	return n.Commit.Hash.String()
 }
 
-func createGraph(commits map[string]*object.Commit, start, end string) (first, last *Node, graph map[string]*Node) {
+func headSHAs(heads []objects.Head) (shas []string) {
+	for _, head := range heads {
+		shas = append(shas, head.SHA)
+	}
+	return shas
+}
+
+// createGraph returns commits which are ancestors of any of heads and descendants of end, or of the first
+// parent root of the first head when end is empty.
+func createGraph(commits map[string]*object.Commit, heads []string, end string) (tips []*Node, root *Node, graph map[string]*Node) {
 	graph = make(map[string]*Node)
 	for k, elem := range commits {
 		graph[k] = &Node{Commit: elem}
@@ -189,21 +310,25 @@ func createGraph(commits map[string]*object.Commit, start, end string) (first, l
 			}
 		}
 	}
-	first = graph[start]
+	for _, head := range heads {
+		if node, ok := graph[head]; ok {
+			tips = append(tips, node)
+		}
+	}
 	if elem, ok := graph[end]; end != "" && ok {
-		last = elem
+		root = elem
 	} else {
-		i := first
+		i := tips[0]
 		for len(i.Parents) > 0 {
 			i = i.Parents[0]
 		}
-		last = i
+		root = i
 	}
 
 	counts := make(map[string]int)
 	var queue []*Node
 	visited := make(map[string]bool)
-	queue = append(queue, first)
+	queue = append(queue, tips...)
 	for len(queue) > 0 {
 		elem := queue[0]
 		queue = queue[1:]
@@ -219,7 +344,7 @@ func createGraph(commits map[string]*object.Commit, start, end string) (first, l
 	}
 
 	visited = make(map[string]bool)
-	queue = append(queue, last)
+	queue = append(queue, root)
 	for len(queue) > 0 {
 		elem := queue[0]
 		queue = queue[1:]
@@ -255,100 +380,164 @@ func createGraph(commits map[string]*object.Commit, start, end string) (first, l
 		node.Children = clearChildren
 	}
 
-	return first, last, graph
+	var inGraph []*Node
+	for _, tip := range tips {
+		if _, ok := graph[tip.SHA()]; ok {
+			inGraph = append(inGraph, tip)
+		}
+	}
+	return inGraph, root, graph
 }
 
-func GetFunctions(src, fileName, pack string) (map[string]*ast.FuncDecl, error) {
+type Declarations struct {
+	Functions map[string]*ast.FuncDecl
+	Types     map[string]*ast.GenDecl
+	Variables map[string]*ast.GenDecl
+	// Splits are names of Variables whose declaration contains other names too
+	Splits map[string]*objects.SplitName
+	// Errors describe declarations which were skipped
+	Errors []error
+	// Generated is set when file has the standard "Code generated ... DO NOT EDIT." header
+	Generated bool
+}
+
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGenerated reports whether src contains generated code header before package clause. Declarations are
+// parsed without comments, so only the header is parsed again here.
+func isGenerated(src, fileName string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), fileName, src, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false
+	}
+	for _, group := range f.Comments {
+		if group.Pos() >= f.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if generatedHeader.MatchString(comment.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func GetDeclarations(src, fileName, pack string) (*Declarations, error) {
+	return getDeclarations(src, fileName, pack, parser.AllErrors)
+}
+
+// GetDeclarationsWithDocs returns declarations with their doc comments.
+func GetDeclarationsWithDocs(src, fileName, pack string) (*Declarations, error) {
+	return getDeclarations(src, fileName, pack, parser.AllErrors|parser.ParseComments)
+}
+
+func getDeclarations(src, fileName, pack string, mode parser.Mode) (*Declarations, error) {
 	fileSet := token.NewFileSet()
-	f, err := parser.ParseFile(fileSet, "", src, parser.AllErrors)
+	f, err := parser.ParseFile(fileSet, fileName, src, mode)
 	if err != nil {
 		return nil, err
 	}
-	functions := make(map[string]*ast.FuncDecl)
-	//variables := make(map[string]*objects.Variable)
+	prefix := pack + "."
+	if pack == "." {
+		prefix = ""
+	}
+	decls := &Declarations{
+		Functions: make(map[string]*ast.FuncDecl),
+		Types:     make(map[string]*ast.GenDecl),
+		Variables: make(map[string]*ast.GenDecl),
+		Splits:    make(map[string]*objects.SplitName),
+		Generated: isGenerated(src, fileName),
+	}
 	for _, decl := range f.Decls {
 		if function, ok := decl.(*ast.FuncDecl); ok {
-			prefix := pack + "."
-			if pack == "." {
-				prefix = ""
+			signature, err := createSignature(function, fileName)
+			if err != nil {
+				decls.Errors = append(decls.Errors, fmt.Errorf("%s: %v", fileSet.Position(function.Pos()), err))
+				continue
 			}
-			functions[prefix+createSignature(function, fileName)] = function
-		}
-		//if v, ok := decl.(*ast.GenDecl); ok {
-		//	switch v.Tok {
-		//	case token.VAR:
-		//		gatherVariables(v, variables)
-		//	case token.TYPE:
-		//	case token.IMPORT:
-		//	case token.CONST:
-		//		gatherVariables(v, variables)
-		//	}
-		//}
-	}
-	//_ = variables
-	return functions, nil
-}
-
-func gatherVariables(v *ast.GenDecl, variables map[string]*objects.Variable) {
-	for _, spec := range v.Specs {
-		value, _ := spec.(*ast.ValueSpec)
-		for i := 0; i < len(value.Names); i++ {
-			v := &objects.Variable{Name: value.Names[i], Type: value.Type}
-			if len(value.Values) > i {
-				v.Expr = value.Values[i]
+			decls.Functions[prefix+signature] = function
+		}
+		if v, ok := decl.(*ast.GenDecl); ok {
+			switch v.Tok {
+			case token.VAR, token.CONST:
+				gatherVariables(v, prefix, decls)
+			case token.TYPE:
+				gatherTypes(v, prefix, decls.Types)
 			}
-			variables[v.Name.Name] = v
 		}
 	}
+	return decls, nil
 }
 
-func createSignature(f *ast.FuncDecl, fileName string) (signature string) {
-	if f == nil {
-		return
+func GetFunctions(src, fileName, pack string) (map[string]*ast.FuncDecl, error) {
+	decls, err := GetDeclarations(src, fileName, pack)
+	if err != nil {
+		return nil, err
 	}
-	name := f.Name.Name
-	if name == "init" {
-		name = fmt.Sprintf("%s[%s]", name, fileName)
+	return decls.Functions, nil
+}
+
+// splitGenDecl returns declaration containing only given spec, so grouped declarations can be tracked per name.
+func splitGenDecl(v *ast.GenDecl, spec ast.Spec) *ast.GenDecl {
+	if len(v.Specs) == 1 && !v.Lparen.IsValid() {
+		return v
 	}
-	if f.Recv != nil {
-		var recv []string
-		for _, param := range f.Recv.List {
-			if len(param.Names) == 0 {
-				recv = append(recv, getType(param.Type))
-			}
-			for range param.Names {
-				recv = append(recv, getType(param.Type))
-			}
-		}
+	return &ast.GenDecl{Doc: v.Doc, TokPos: spec.Pos(), Tok: v.Tok, Specs: []ast.Spec{spec}}
+}
 
-		return strings.Join(recv, ",") + "." + name
+func gatherTypes(v *ast.GenDecl, prefix string, types map[string]*ast.GenDecl) {
+	for _, spec := range v.Specs {
+		typeSpec, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		types[prefix+typeSpec.Name.Name] = splitGenDecl(v, spec)
 	}
-	return name
 }
 
-func getType(x ast.Node) string {
-	if x == nil {
-		return ""
-	}
-	switch t := x.(type) {
-	case *ast.Ident:
-		return t.Name
-	case *ast.SelectorExpr:
-		return getType(t.X) + "." + t.Sel.Name
-	case *ast.StarExpr:
-		return getType(t.X)
-	case *ast.ArrayType:
-		return "[" + getType(t.Len) + "]" + getType(t.Elt)
-	case *ast.MapType:
-		return "map[" + getType(t.Key) + "]" + getType(t.Value)
-	case *ast.InterfaceType:
-		if len(t.Methods.List) == 0 {
-			return "interface{}"
-		} else {
-			panic(reflect.TypeOf(t))
-			return ""
+// spanGenDecl returns declaration of specs whose text spans source from pos to end.
+func spanGenDecl(v *ast.GenDecl, specs []ast.Spec, pos, end token.Pos) *ast.GenDecl {
+	// Rparen ends the declaration at the last spec
+	return &ast.GenDecl{Doc: v.Doc, TokPos: pos, Tok: v.Tok, Specs: specs, Rparen: end - 1}
+}
+
+func gatherVariables(v *ast.GenDecl, prefix string, decls *Declarations) {
+	source := 0
+	for i, spec := range v.Specs {
+		value, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if len(value.Values) > 0 {
+			source = i
+		}
+		for j, name := range value.Names {
+			if name.Name == "_" {
+				continue
+			}
+			id := prefix + name.Name
+			switch {
+			case v.Tok == token.CONST && len(value.Values) == 0 && i > source:
+				// constant repeats type and expression of the last spec with values, like iota
+				repeated := v.Specs[source].(*ast.ValueSpec)
+				single := &ast.ValueSpec{Names: []*ast.Ident{name}, Type: repeated.Type}
+				if len(repeated.Values) > j {
+					single.Values = []ast.Expr{repeated.Values[j]}
+				}
+				decls.Variables[id] = spanGenDecl(v, v.Specs[source:i+1], repeated.Pos(), value.End())
+				decls.Splits[id] = &objects.SplitName{Spec: single, Index: i - source}
+			case len(value.Names) == 1 || len(value.Values) != 0 && len(value.Values) != len(value.Names):
+				// values of multi-value expression can not be split between names
+				decls.Variables[id] = splitGenDecl(v, spec)
+			default:
+				single := &ast.ValueSpec{Doc: value.Doc, Names: []*ast.Ident{name}, Type: value.Type, Comment: value.Comment}
+				if len(value.Values) > j {
+					single.Values = []ast.Expr{value.Values[j]}
+				}
+				decls.Variables[id] = spanGenDecl(v, []ast.Spec{single}, value.Pos(), value.End())
+				decls.Splits[id] = &objects.SplitName{Spec: single}
+			}
 		}
-	default:
-		panic(reflect.TypeOf(t))
 	}
 }
@@ -2,16 +2,235 @@ package collector
 
 import (
 	"fmt"
+	"go/ast"
+	"reflect"
 	"testing"
+
+	"github.com/wookesh/gohist/objects"
+	"gopkg.in/src-d/go-git.v4"
 )
 
 func TestA(t *testing.T) {
 
-	history, err := CreateHistory("..", "4a89114ba35dd28ed81f11ec3eba769a401789a5", "", false)
-	//history, err := CreateHistory("..", "master", "", false)
+	history, err := CreateHistory("..", Options{Start: "4a89114ba35dd28ed81f11ec3eba769a401789a5"})
+	//history, err := CreateHistory("..", Options{Start: "master"})
 	if err != nil {
 		fmt.Println(err)
 	}
 	fmt.Println(history)
 
 }
+
+func TestGetDeclarationsGenerics(t *testing.T) {
+	src := `package p
+
+type Stack[T any] struct {
+	items []T
+}
+
+func (s *Stack[T]) Push(v T) {
+	s.items = append(s.items, v)
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+func (p Pair[K, V]) Swap() Pair[V, K] {
+	return Pair[V, K]{p.Value, p.Key}
+}
+
+func Map[T, U any](xs []T, f func(T) U) []U {
+	return nil
+}
+`
+	decls, err := GetDeclarations(src, "p.go", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"p.Stack.Push", "p.Pair.Swap", "p.Map"} {
+		if _, ok := decls.Functions[id]; !ok {
+			t.Errorf("function %s not found in %v", id, decls.Functions)
+		}
+	}
+	for _, id := range []string{"p.Stack", "p.Pair"} {
+		if _, ok := decls.Types[id]; !ok {
+			t.Errorf("type %s not found in %v", id, decls.Types)
+		}
+	}
+}
+
+func TestGetDeclarationsGenerated(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		generated bool
+	}{
+		{"protoc", "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: a.proto\n\npackage p\n", true},
+		{"stringer", "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage p\n", true},
+		{"build tag", "//go:build linux\n\n// Code generated by mockgen. DO NOT EDIT.\n\npackage p\n", true},
+		{"handwritten", "// Package p does things.\npackage p\n", false},
+		{"missing dot", "// Code generated by hand. DO NOT EDIT\npackage p\n", false},
+		{"after package", "package p\n\n// Code generated by protoc-gen-go. DO NOT EDIT.\n", false},
+		{"block comment", "/* Code generated by tool. DO NOT EDIT. */\npackage p\n", false},
+	}
+	for _, tt := range tests {
+		decls, err := GetDeclarations(tt.src, "p.go", "p")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if decls.Generated != tt.generated {
+			t.Errorf("%s: generated = %v, want %v", tt.name, decls.Generated, tt.generated)
+		}
+	}
+}
+
+func TestGetDeclarationsWithDocs(t *testing.T) {
+	src := "package p\n\n// F does things.\n//\n// More details.\nfunc F() {}\n\n/* G is old style. */\nfunc G() {}\n\nfunc H() {} // not a doc\n"
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"F", "// F does things.\n//\n// More details."},
+		{"G", "/* G is old style. */"},
+		{"H", ""},
+	}
+	decls, err := GetDeclarationsWithDocs(src, "p.go", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := GetDeclarations(src, "p.go", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		if doc := objects.DocText(decls.Functions[tt.name]); doc != tt.doc {
+			t.Errorf("%s: doc = %q, want %q", tt.name, doc, tt.doc)
+		}
+		if doc := objects.DocText(plain.Functions[tt.name]); doc != "" {
+			t.Errorf("%s: doc without comments = %q", tt.name, doc)
+		}
+	}
+}
+
+func TestGetDeclarationsVariables(t *testing.T) {
+	src := "package p\n\nvar a, b = f()\n\nvar c, d = 1, 2\n\nconst (\n\tA = iota\n\tB\n\tC\n)\n\n" +
+		"const (\n\tX T = iota + 1\n\tY\n)\n\nconst (\n\tP = 1\n\tQ = 2\n\tR\n)\n\nvar (\n\tm, n int\n\t_, o = 3, 4\n)\n"
+	tests := []struct {
+		name   string
+		text   string
+		values []string
+	}{
+		{"a", "var a, b = f()", []string{"f()"}},
+		{"b", "var a, b = f()", []string{"f()"}},
+		{"c", "c, d = 1, 2", []string{"1"}},
+		{"d", "c, d = 1, 2", []string{"2"}},
+		{"A", "A = iota", []string{"iota"}},
+		{"B", "A = iota\n\tB", []string{"iota"}},
+		{"C", "A = iota\n\tB\n\tC", []string{"iota"}},
+		{"Y", "X T = iota + 1\n\tY", []string{"iota + 1"}},
+		{"R", "Q = 2\n\tR", []string{"2"}},
+		{"m", "m, n int", nil},
+		{"o", "_, o = 3, 4", []string{"4"}},
+	}
+	decls, err := GetDeclarations(src, "p.go", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decls.Variables) != 15 {
+		t.Errorf("got %d variables, want 15", len(decls.Variables))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decl, ok := decls.Variables[tt.name]
+			if !ok {
+				t.Fatalf("%s not found", tt.name)
+			}
+			if text := src[decl.Pos()-1 : decl.End()-1]; text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			var values []string
+			for _, spec := range decl.Specs {
+				for _, value := range spec.(*ast.ValueSpec).Values {
+					values = append(values, src[value.Pos()-1:value.End()-1])
+				}
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("values = %q, want %q", values, tt.values)
+			}
+		})
+	}
+}
+
+func TestCreateHistorySimpleVariables(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []string{
+		"var a, b = 1+1, 2\n",
+		"var a, b = 1+1, 3\n",
+		"var a, b = 1 + 1, 3\n",
+	}
+	for i, src := range versions {
+		commitFile(t, repo, dir, i, src)
+	}
+	history, err := CreateHistory(dir, Options{Start: "HEAD", Simple: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// changed value of b does not change a, formatting of a's own value does
+	for id, want := range map[string]int{"a": 2, "b": 2} {
+		vh := history.Variables[id]
+		if vh == nil {
+			t.Fatalf("%s not found", id)
+		}
+		if vh.VersionsCount() != want {
+			t.Errorf("%s has %d versions, want %d", id, vh.VersionsCount(), want)
+		}
+	}
+}
+
+func TestCreateHistoryIotaRename(t *testing.T) {
+	versions := []string{
+		"const (\n\tA = iota\n\tB\n\tC\n)\n",
+		"const (\n\tZ = iota\n\tB\n\tC\n)\n",
+		"const (\n\tY = iota\n\tB\n\tC\n)\n",
+		"const (\n\tY = iota\n\tC\n\tB\n)\n",
+	}
+	for _, simple := range []bool{false, true} {
+		t.Run(fmt.Sprintf("simple=%v", simple), func(t *testing.T) {
+			dir := t.TempDir()
+			repo, err := git.PlainInit(dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := Options{Start: "HEAD", Simple: simple, CacheDir: t.TempDir()}
+			for i, src := range versions[:2] {
+				commitFile(t, repo, dir, i, src)
+			}
+			if _, err := CreateHistory(dir, opts); err != nil {
+				t.Fatal(err)
+			}
+			for i, src := range versions[2:] {
+				commitFile(t, repo, dir, i+2, src)
+			}
+			history, err := CreateHistory(dir, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// renames of the iota constant do not change the constants repeating it, moving them does
+			for _, id := range []string{"B", "C"} {
+				vh := history.Variables[id]
+				if vh == nil {
+					t.Fatalf("%s not found", id)
+				}
+				if vh.VersionsCount() != 2 {
+					t.Errorf("%s has %d versions, want 2", id, vh.VersionsCount())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+func createSignature(f *ast.FuncDecl, fileName string) (string, error) {
+	if f == nil {
+		return "", nil
+	}
+	name := f.Name.Name
+	if name == "init" {
+		name = fmt.Sprintf("%s[%s]", name, fileName)
+	}
+	if f.Recv != nil {
+		var recv []string
+		for _, param := range f.Recv.List {
+			t, err := receiverType(param.Type)
+			if err != nil {
+				return "", fmt.Errorf("receiver of %s: %v", name, err)
+			}
+			if len(param.Names) == 0 {
+				recv = append(recv, t)
+			}
+			for range param.Names {
+				recv = append(recv, t)
+			}
+		}
+
+		return strings.Join(recv, ",") + "." + name, nil
+	}
+	return name, nil
+}
+
+// receiverType returns name of receiver base type, pointer, parentheses and type parameters are not part
+// of method identity.
+func receiverType(x ast.Expr) (string, error) {
+	switch t := x.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.ParenExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	default:
+		return getType(x)
+	}
+}
+
+// getType prints type expression on one line, it fails on missing and bad expressions which can't be printed.
+func getType(x ast.Expr) (string, error) {
+	if x == nil {
+		return "", fmt.Errorf("missing type expression")
+	}
+	var err error
+	ast.Inspect(x, func(n ast.Node) bool {
+		if _, ok := n.(*ast.BadExpr); ok {
+			err = fmt.Errorf("bad type expression at %d", n.Pos())
+		}
+		return err == nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return types.ExprString(x), nil
+}
@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestCreateSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"function", "func F() {}", "F"},
+		{"init", "func init() {}", "init[p.go]"},
+		{"value receiver", "func (s S) F() {}", "S.F"},
+		{"pointer receiver", "func (s *S) F() {}", "S.F"},
+		{"unnamed receiver", "func (*S) F() {}", "S.F"},
+		{"parenthesized receiver", "func (s (*S)) F() {}", "S.F"},
+		{"generic receiver", "func (s *S[T]) F() {}", "S.F"},
+		{"generic receiver with many parameters", "func (s S[K, V]) F() {}", "S.F"},
+		{"parenthesized generic receiver", "func (s *(S[T])) F() {}", "S.F"},
+		{"qualified receiver", "func (s pkg.S) F() {}", "pkg.S.F"},
+		{"map receiver", "func (m map[string][]int) F() {}", "map[string][]int.F"},
+		{"array receiver", "func (a [2 * N]int) F() {}", "[2 * N]int.F"},
+		{"channel receiver", "func (c <-chan chan<- int) F() {}", "<-chan chan<- int.F"},
+		{"func receiver", "func (f func(int, ...string) (string, error)) F() {}", "func(int, ...string) (string, error).F"},
+		{"empty interface receiver", "func (i interface{}) F() {}", "interface{}.F"},
+		{"interface receiver", "func (i interface{ M(x int) bool; io.Reader }) F() {}", "interface{M(x int) bool; io.Reader}.F"},
+		{"struct receiver", "func (s struct{ a, b int `json:\"a\"`; c string }) F() {}", "struct{a, b int; c string}.F"},
+		{"constraint receiver", "func (s interface{ ~int | ~string }) F() {}", "interface{~int | ~string}.F"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), "p.go", "package p\n"+tt.src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := createSignature(f.Decls[0].(*ast.FuncDecl), "p.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("createSignature() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSignatureError(t *testing.T) {
+	f := &ast.FuncDecl{
+		Name: ast.NewIdent("F"),
+		Recv: &ast.FieldList{List: []*ast.Field{{Type: &ast.BadExpr{}}}},
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+	}
+	if _, err := createSignature(f, "p.go"); err == nil {
+		t.Error("createSignature() should fail on bad receiver")
+	}
+	f.Recv.List[0].Type = &ast.StarExpr{X: &ast.IndexExpr{X: &ast.BadExpr{}, Index: ast.NewIdent("T")}}
+	if _, err := createSignature(f, "p.go"); err == nil {
+		t.Error("createSignature() should fail on bad generic receiver")
+	}
+	f.Recv.List[0].Type = nil
+	if _, err := createSignature(f, "p.go"); err == nil {
+		t.Error("createSignature() should fail on missing receiver type")
+	}
+}
@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsSameAlpha(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"same", "func F() error { err2 := g(); return err2 }", "func F() error { err2 := g(); return err2 }", true},
+		{"local rename", "func F() error { err2 := g(); return err2 }", "func F() error { readErr := g(); return readErr }", true},
+		{"param rename", "func F(a, b int) int { return a - b }", "func F(x, y int) int { return x - y }", true},
+		{"range rename", "func F(xs []int) { for i, x := range xs { use(i, x) } }", "func F(xs []int) { for j, v := range xs { use(j, v) } }", true},
+		{"swapped", "func F(a, b int) int { return a - b }", "func F(a, b int) int { return b - a }", false},
+		{"merged", "func F() int { a, b := 1, 2; return a + b }", "func F() int { a, c := 1, 2; return a + a }", false},
+		{"global", "func F() int { return x }", "func F() int { return y }", false},
+		{"local to global", "func F() int { x := 1; return x }", "func F() int { x := 1; return y }", false},
+		{"blank", "func F() { _, err := g(); use(err) }", "func F() { v, err := g(); use(err) }", false},
+		{"literal", "func F() int { x := 1; return x }", "func F() int { y := 2; return y }", false},
+		{"name", "func F() {}", "func G() {}", false},
+		{"field", "func F(s S) int { x := s.x; return x }", "func F(s S) int { y := s.y; return y }", false},
+		{"key", "func F() T { x := 1; return T{x: x} }", "func F() T { y := 1; return T{y: y} }", false},
+		{"receiver", "func (s *S) F() int { return s.n }", "func (t *S) F() int { return t.n }", true},
+		{"shadowed", "func F(a int) int { if b := a; b > 0 { return b }; b := 1; return b }", "func F(a int) int { if c := a; c > 0 { return c }; b := 1; return b }", true},
+		{"label", "func F() { L: for { break L } }", "func F() { M: for { break M } }", true},
+		{"closure", "func F() func() int { x := 1; return func() int { return x } }", "func F() func() int { y := 1; return func() int { return y } }", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parseDecl(t, tt.a), parseDecl(t, tt.b)
+			if same := IsSameAlpha(a, b); same != tt.same {
+				t.Errorf("IsSameAlpha() = %v, want %v", same, tt.same)
+			}
+			if coloring := DiffAlpha(a, b, ModeOld); tt.same && len(coloring) != 0 {
+				t.Errorf("DiffAlpha() = %v, want no changes", coloring)
+			}
+		})
+	}
+}
+
+func TestDiffAlpha(t *testing.T) {
+	tests := []struct {
+		name           string
+		old, new       string
+		removed, added []string
+	}{
+		{"rename", "func F() int { x := 1; return x }", "func F() int { y := 1; return y }", nil, nil},
+		{"rename and edit", "func F(a int) int { x := a; return x + 1 }", "func F(b int) int { y := b; return y * 2 }", []string{"x + 1"}, []string{"y * 2"}},
+		{"rename and literal", "func F() int { x := 1; return x + 1 }", "func F() int { y := 1; return y + 2 }", []string{"1"}, []string{"2"}},
+		{"field", "func F(s S) int { return s.x }", "func F(t S) int { return t.y }", []string{"x"}, []string{"y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parseDecl(t, tt.old), parseDecl(t, tt.new)
+			if got := colored(tt.old, DiffAlpha(a, b, ModeOld)); !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("removed = %q, want %q", got, tt.removed)
+			}
+			if got := colored(tt.new, DiffAlpha(b, a, ModeNew)); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("added = %q, want %q", got, tt.added)
+			}
+			left, right := DiffAlphaPair(a, b)
+			if got := colored(tt.old, left); !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("DiffAlphaPair() removed = %q, want %q", got, tt.removed)
+			}
+			if got := colored(tt.new, right); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("DiffAlphaPair() added = %q, want %q", got, tt.added)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// BreakingChanges describes incompatible changes of exported function signature from a to b, changes of parameter
+// and result names are compatible.
+func BreakingChanges(a, b *ast.FuncDecl) (changes []string) {
+	if a == nil || b == nil || !Exported(a) {
+		return nil
+	}
+	if !Exported(b) {
+		return []string{"unexported"}
+	}
+	if !sameFieldTypes(a.Recv, b.Recv) {
+		changes = append(changes, fmt.Sprintf("receiver changed from %s to %s", typeList(a.Recv), typeList(b.Recv)))
+	}
+	if !sameFieldTypes(a.Type.TypeParams, b.Type.TypeParams) {
+		changes = append(changes, fmt.Sprintf("type parameters changed from [%s] to [%s]",
+			typeList(a.Type.TypeParams), typeList(b.Type.TypeParams)))
+	}
+	aParams, bParams := fieldTypes(a.Type.Params), fieldTypes(b.Type.Params)
+	for i := 0; i < len(aParams) && i < len(bParams); i++ {
+		if !IsSame(aParams[i], bParams[i]) {
+			changes = append(changes, fmt.Sprintf("parameter %d retyped from %s to %s", i+1,
+				types.ExprString(aParams[i]), types.ExprString(bParams[i])))
+		}
+	}
+	for i := len(aParams); i < len(bParams); i++ {
+		changes = append(changes, fmt.Sprintf("parameter %d added: %s", i+1, types.ExprString(bParams[i])))
+	}
+	for i := len(bParams); i < len(aParams); i++ {
+		changes = append(changes, fmt.Sprintf("parameter %d removed: %s", i+1, types.ExprString(aParams[i])))
+	}
+	if !sameFieldTypes(a.Type.Results, b.Type.Results) {
+		changes = append(changes, fmt.Sprintf("results changed from (%s) to (%s)",
+			typeList(a.Type.Results), typeList(b.Type.Results)))
+	}
+	return changes
+}
+
+// Exported reports whether function is exported, methods must have exported receiver type too.
+func Exported(f *ast.FuncDecl) bool {
+	if !f.Name.IsExported() {
+		return false
+	}
+	if f.Recv == nil || len(f.Recv.List) == 0 {
+		return true
+	}
+	recv := f.Recv.List[0].Type
+	for {
+		switch t := recv.(type) {
+		case *ast.StarExpr:
+			recv = t.X
+		case *ast.ParenExpr:
+			recv = t.X
+		case *ast.IndexExpr:
+			recv = t.X
+		case *ast.IndexListExpr:
+			recv = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+func typeList(fields *ast.FieldList) string {
+	var list []string
+	for _, t := range fieldTypes(fields) {
+		list = append(list, types.ExprString(t))
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,38 @@
+package diff
+
+import (
+	"go/ast"
+	"reflect"
+	"testing"
+)
+
+func TestBreakingChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		changes []string
+	}{
+		{"body", "func F(x int) int { return x }", "func F(x int) int { return x + 1 }", nil},
+		{"param rename", "func F(x int) {}", "func F(y int) {}", nil},
+		{"grouped params", "func F(x, y int) {}", "func F(x int, y int) {}", nil},
+		{"unexported", "func f(x int) {}", "func f(x string) {}", nil},
+		{"unexported receiver", "func (t t) F(x int) {}", "func (t t) F() {}", nil},
+		{"param retyped", "func F(x int) {}", "func F(x int64) {}", []string{"parameter 1 retyped from int to int64"}},
+		{"param added", "func F(x int) {}", "func F(x int, opts ...Option) {}", []string{"parameter 2 added: ...Option"}},
+		{"param removed", "func F(x, y int) {}", "func F(x int) {}", []string{"parameter 2 removed: int"}},
+		{"result added", "func F() {}", "func F() error { return nil }", []string{"results changed from () to (error)"}},
+		{"result named", "func F() error { return nil }", "func F() (err error) { return }", nil},
+		{"receiver", "func (t T) F() {}", "func (t *T) F() {}", []string{"receiver changed from T to *T"}},
+		{"type parameters", "func F[T any](x T) {}", "func F[T comparable](x T) {}", []string{"type parameters changed from [any] to [comparable]"}},
+		{"multiple", "func F(x int) int { return x }", "func F(x string) string { return x }",
+			[]string{"parameter 1 retyped from int to string", "results changed from (int) to (string)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parseDecl(t, tt.a).(*ast.FuncDecl), parseDecl(t, tt.b).(*ast.FuncDecl)
+			if changes := BreakingChanges(a, b); !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("BreakingChanges() = %q, want %q", changes, tt.changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package diff
+
+import (
+	"fmt"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// Category is set of kinds of change between two versions of declaration.
+type Category uint
+
+const (
+	// CategorySignature is change of function name, receiver, type parameters, parameter or result types.
+	CategorySignature Category = 1 << iota
+	// CategoryBody is change of function body or type and variable definition not covered by other categories.
+	CategoryBody
+	// CategoryRename is consistent rename of local variables and parameters.
+	CategoryRename
+	// CategoryLiteral is change of literal values only.
+	CategoryLiteral
+	// CategoryControlFlow is change of control flow statements structure.
+	CategoryControlFlow
+	// CategoryFormatting is change of text which changes neither AST nor comments.
+	CategoryFormatting
+	// CategoryComments is change of comments which does not change AST.
+	CategoryComments
+)
+
+var categoryNames = []string{"signature", "body", "rename", "literal", "control_flow", "formatting", "comments"}
+
+// Categories lists all categories in order of their names.
+var Categories = []Category{CategorySignature, CategoryBody, CategoryRename, CategoryLiteral, CategoryControlFlow,
+	CategoryFormatting, CategoryComments}
+
+func (c Category) Names() (names []string) {
+	for i, name := range categoryNames {
+		if c&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (c Category) String() string {
+	return strings.Join(c.Names(), ",")
+}
+
+func ParseCategory(name string) (Category, error) {
+	for i, n := range categoryNames {
+		if n == name {
+			return 1 << uint(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown change category: %s", name)
+}
+
+// Classify returns categories of change from a to b, it is empty when a is nil. Nodes with the same AST are classified
+// as formatting, use ClassifyText to tell comment changes apart.
+func Classify(a, b ast.Node) Category {
+	if a == nil || b == nil {
+		return 0
+	}
+	if IsSame(a, b) {
+		return CategoryFormatting
+	}
+	var category Category
+	var aNodes, bNodes []ast.Node
+	aFunc, aOk := a.(*ast.FuncDecl)
+	bFunc, bOk := b.(*ast.FuncDecl)
+	if aOk && bOk {
+		signature := !sameSignature(aFunc, bFunc)
+		if signature {
+			category |= CategorySignature
+		} else {
+			aNodes, bNodes = append(aNodes, aFunc.Type), append(bNodes, bFunc.Type)
+			if aFunc.Recv != nil {
+				aNodes, bNodes = append(aNodes, aFunc.Recv), append(bNodes, bFunc.Recv)
+			}
+		}
+		if (aFunc.Body == nil) != (bFunc.Body == nil) {
+			return category | CategoryBody
+		}
+		if aFunc.Body != nil {
+			aNodes, bNodes = append(aNodes, aFunc.Body), append(bNodes, bFunc.Body)
+			if !sameControlFlow(aFunc.Body, bFunc.Body) {
+				category |= CategoryControlFlow
+			}
+		}
+	} else {
+		aNodes, bNodes = []ast.Node{a}, []ast.Node{b}
+	}
+
+	aLeaves, bLeaves := flatten(aNodes), flatten(bNodes)
+	if len(aLeaves) != len(bLeaves) {
+		return category | CategoryBody
+	}
+	ctx := newContext(a, b)
+	var literal, rename bool
+	for i := range aLeaves {
+		x, y := aLeaves[i], bLeaves[i]
+		switch {
+		case x.kind != y.kind:
+			return category | CategoryBody
+		case x.ident != nil:
+			if !ctx.sameIdent(x.ident, y.ident) {
+				return category | CategoryBody
+			}
+			rename = rename || x.value != y.value
+		case x.value == y.value:
+		case strings.HasPrefix(x.kind, "BasicLit"):
+			literal = true
+		default:
+			return category | CategoryBody
+		}
+	}
+	if literal {
+		category |= CategoryLiteral
+	}
+	if rename {
+		category |= CategoryRename
+	}
+	return category
+}
+
+// ClassifyText refines category of change from a to b with their text, changed comments turn formatting into
+// comments change and identical text is not a change at all.
+func ClassifyText(category Category, a, b string) Category {
+	if category != CategoryFormatting {
+		return category
+	}
+	switch {
+	case a == b:
+		return 0
+	case !sameComments(a, b):
+		return CategoryComments
+	}
+	return category
+}
+
+func sameComments(a, b string) bool {
+	aComments, bComments := comments(a), comments(b)
+	if len(aComments) != len(bComments) {
+		return false
+	}
+	for i := range aComments {
+		if aComments[i] != bComments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// comments returns text of comments in src with trailing spaces removed.
+func comments(src string) (result []string) {
+	var s scanner.Scanner
+	file := token.NewFileSet().AddFile("", -1, len(src))
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			return result
+		}
+		if tok == token.COMMENT {
+			result = append(result, strings.TrimRight(lit, " \t"))
+		}
+	}
+}
+
+// sameSignature compares function identity and types of its parameters and results, parameter names are ignored.
+func sameSignature(a, b *ast.FuncDecl) bool {
+	if a.Name.Name != b.Name.Name || !IsSame(a.Type.TypeParams, b.Type.TypeParams) {
+		return false
+	}
+	return sameFieldTypes(a.Recv, b.Recv) && sameFieldTypes(a.Type.Params, b.Type.Params) &&
+		sameFieldTypes(a.Type.Results, b.Type.Results)
+}
+
+func sameFieldTypes(a, b *ast.FieldList) bool {
+	aTypes, bTypes := fieldTypes(a), fieldTypes(b)
+	if len(aTypes) != len(bTypes) {
+		return false
+	}
+	for i := range aTypes {
+		if !IsSame(aTypes[i], bTypes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldTypes returns type of every declared field, fields declared together repeat their type.
+func fieldTypes(fields *ast.FieldList) (types []ast.Expr) {
+	if fields == nil {
+		return nil
+	}
+	for _, field := range fields.List {
+		for i := 0; i < len(field.Names) || (i == 0 && len(field.Names) == 0); i++ {
+			types = append(types, field.Type)
+		}
+	}
+	return types
+}
+
+type leaf struct {
+	kind, value string
+	ident       *ast.Ident
+}
+
+// flatten serializes nodes to sequence of node descriptions with markers closing every node, so sequences are
+// equal only when trees have the same shape. Comments are skipped.
+func flatten(nodes []ast.Node) (leaves []leaf) {
+	for _, node := range nodes {
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch n.(type) {
+			case nil:
+				leaves = append(leaves, leaf{kind: ")"})
+				return true
+			case *ast.CommentGroup:
+				return false
+			}
+			leaves = append(leaves, describe(n))
+			return true
+		})
+	}
+	return leaves
+}
+
+// describe returns node type with operators and flags of optional children, identifiers and literals have values.
+func describe(node ast.Node) leaf {
+	kind := fmt.Sprintf("%T", node)
+	switch n := node.(type) {
+	case *ast.Ident:
+		return leaf{kind: "Ident", value: n.Name, ident: n}
+	case *ast.BasicLit:
+		return leaf{kind: "BasicLit" + n.Kind.String(), value: n.Value}
+	case *ast.BinaryExpr:
+		kind += n.Op.String()
+	case *ast.UnaryExpr:
+		kind += n.Op.String()
+	case *ast.AssignStmt:
+		kind += n.Tok.String()
+	case *ast.IncDecStmt:
+		kind += n.Tok.String()
+	case *ast.BranchStmt:
+		kind += n.Tok.String()
+	case *ast.GenDecl:
+		kind += n.Tok.String()
+	case *ast.RangeStmt:
+		kind += fmt.Sprint(n.Tok, n.Key != nil, n.Value != nil)
+	case *ast.ChanType:
+		kind += fmt.Sprint(n.Dir)
+	case *ast.CallExpr:
+		kind += fmt.Sprint(n.Ellipsis.IsValid())
+	case *ast.SliceExpr:
+		kind += fmt.Sprint(n.Low != nil, n.High != nil, n.Max != nil, n.Slice3)
+	case *ast.ForStmt:
+		kind += fmt.Sprint(n.Init != nil, n.Cond != nil, n.Post != nil)
+	case *ast.IfStmt:
+		kind += fmt.Sprint(n.Init != nil, n.Else != nil)
+	case *ast.SwitchStmt:
+		kind += fmt.Sprint(n.Init != nil, n.Tag != nil)
+	case *ast.TypeSwitchStmt:
+		kind += fmt.Sprint(n.Init != nil)
+	case *ast.FuncType:
+		kind += fmt.Sprint(n.TypeParams != nil, n.Results != nil)
+	case *ast.FuncDecl:
+		kind += fmt.Sprint(n.Recv != nil)
+	case *ast.ValueSpec:
+		kind += fmt.Sprint(len(n.Names), n.Type != nil, len(n.Values))
+	case *ast.TypeSpec:
+		kind += fmt.Sprint(n.TypeParams != nil, n.Assign.IsValid())
+	case *ast.ArrayType:
+		kind += fmt.Sprint(n.Len != nil)
+	case *ast.Ellipsis:
+		kind += fmt.Sprint(n.Elt != nil)
+	case *ast.Field:
+		kind += fmt.Sprint(len(n.Names), n.Tag != nil)
+	case *ast.CaseClause:
+		kind += fmt.Sprint(n.List == nil)
+	case *ast.CommClause:
+		kind += fmt.Sprint(n.Comm == nil)
+	case *ast.CompositeLit:
+		kind += fmt.Sprint(n.Type != nil)
+	}
+	return leaf{kind: kind}
+}
+
+// sameControlFlow compares nesting and order of branching, looping and jump statements.
+func sameControlFlow(a, b ast.Node) bool {
+	aFlow, bFlow := controlFlow(a), controlFlow(b)
+	if len(aFlow) != len(bFlow) {
+		return false
+	}
+	for i := range aFlow {
+		if aFlow[i] != bFlow[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func controlFlow(node ast.Node) (flow []string) {
+	var stack []bool
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			if stack[len(stack)-1] {
+				flow = append(flow, ")")
+			}
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		var kind string
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			kind = fmt.Sprint("if", n.Else != nil)
+		case *ast.ForStmt, *ast.RangeStmt:
+			kind = "for"
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt:
+			kind = "switch"
+		case *ast.SelectStmt:
+			kind = "select"
+		case *ast.CaseClause, *ast.CommClause:
+			kind = "case"
+		case *ast.ReturnStmt:
+			kind = "return"
+		case *ast.BranchStmt:
+			kind = n.Tok.String()
+		case *ast.GoStmt:
+			kind = "go"
+		case *ast.DeferStmt:
+			kind = "defer"
+		case *ast.LabeledStmt:
+			kind = "label"
+		case *ast.FuncLit:
+			kind = "func"
+		}
+		if kind != "" {
+			flow = append(flow, kind)
+		}
+		stack = append(stack, kind != "")
+		return true
+	})
+	return flow
+}
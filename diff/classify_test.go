@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		category Category
+	}{
+		{"same", "func F(x int) int { return x + 1 }", "func F(x int) int { return x + 1 }", CategoryFormatting},
+		{"param type", "func F(x int) int { return x }", "func F(x int64) int { return x }", CategorySignature},
+		{"result added", "func F(x int) { _ = x }", "func F(x int) error { _ = x; return nil }", CategorySignature | CategoryBody | CategoryControlFlow},
+		{"receiver", "func (s S) F() {}", "func (s *S) F() {}", CategorySignature},
+		{"param rename", "func F(x int) int { return x * 2 }", "func F(y int) int { return y * 2 }", CategoryRename},
+		{"local rename", "func F() int { a := 1; b := a; return b }", "func F() int { x := 1; b := x; return b }", CategoryRename},
+		{"inconsistent rename", "func F() int { a, b := 1, 2; return a + b }", "func F() int { a, b := 1, 2; return b + b }", CategoryBody},
+		{"global rename", "func F() int { return A }", "func F() int { return B }", CategoryBody},
+		{"literal", `func F() string { return "a" + "b" }`, `func F() string { return "a" + "c" }`, CategoryLiteral},
+		{"literal and rename", "func F(x int) int { return x + 1 }", "func F(y int) int { return y + 2 }", CategoryLiteral | CategoryRename},
+		{"call", "func F() { f() }", "func F() { g() }", CategoryBody},
+		{"operator", "func F(x int) int { return x + 1 }", "func F(x int) int { return x - 1 }", CategoryBody},
+		{"if added", "func F(x int) int { return x }", "func F(x int) int { if x < 0 { return 0 }; return x }", CategoryBody | CategoryControlFlow},
+		{"condition", "func F(x int) int { if x < 0 { return 0 }; return x }", "func F(x int) int { if x > 0 { return 0 }; return x }", CategoryBody},
+		{"break to continue", "func F() { for { break } }", "func F() { for { continue } }", CategoryBody | CategoryControlFlow},
+		{"slice bounds", "func F(a []int) []int { return a[1:] }", "func F(a []int) []int { return a[:1] }", CategoryBody},
+		{"const value", "const A = 1", "const A = 2", CategoryLiteral},
+		{"field type", "type T struct{ x int }", "type T struct{ x string }", CategoryBody},
+		{"selected field", "func F(s S) int { x := s.x; return x }", "func F(s S) int { y := s.y; return y }", CategoryBody},
+		{"literal key", "func F() T { x := 1; return T{x: x} }", "func F() T { y := 1; return T{y: y} }", CategoryBody},
+		{"receiver rename", "func (s *S) F() int { return s.n }", "func (t *S) F() int { return t.n }", CategoryRename},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if category := Classify(parseDecl(t, tt.a), parseDecl(t, tt.b)); category != tt.category {
+				t.Errorf("Classify() = %v, want %v", category, tt.category)
+			}
+		})
+	}
+	if category := Classify(nil, parseDecl(t, "func F() {}")); category != 0 {
+		t.Errorf("Classify(nil) = %v, want none", category)
+	}
+}
+
+func TestClassifyText(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		category Category
+	}{
+		{"same", "func F() { f() }", "func F() { f() }", 0},
+		{"formatting", "func F() { f() }", "func F() {\n\tf()\n}", CategoryFormatting},
+		{"comment added", "func F() { f() }", "func F() {\n\t// call f\n\tf()\n}", CategoryComments},
+		{"comment changed", "func F() { f() /* a */ }", "func F() { f() /* b */ }", CategoryComments},
+		{"comment moved", "func F() {\n\t// c\n\tf()\n}", "func F() {\n\tf() // c\n}", CategoryFormatting},
+		{"body", "func F() { f() }", "func F() { g() }", CategoryBody},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category := ClassifyText(Classify(parseDecl(t, tt.a), parseDecl(t, tt.b)), tt.a, tt.b)
+			if category != tt.category {
+				t.Errorf("ClassifyText() = %v, want %v", category, tt.category)
+			}
+		})
+	}
+}
+
+func TestParseCategory(t *testing.T) {
+	for _, category := range Categories {
+		parsed, err := ParseCategory(category.String())
+		if err != nil || parsed != category {
+			t.Errorf("ParseCategory(%q) = %v, %v", category.String(), parsed, err)
+		}
+	}
+	if _, err := ParseCategory("unknown"); err == nil {
+		t.Error("ParseCategory(unknown) did not fail")
+	}
+}
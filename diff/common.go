@@ -16,8 +16,26 @@ const (
 	ColorNew
 	ColorRemoved
 	ColorSimilar
+	ColorMoved
 )
 
+func (c Color) String() string {
+	switch c {
+	case ColorSame:
+		return "same"
+	case ColorNew:
+		return "new"
+	case ColorRemoved:
+		return "removed"
+	case ColorSimilar:
+		return "similar"
+	case ColorMoved:
+		return "moved"
+	default:
+		return ""
+	}
+}
+
 type Mode int
 
 const (
@@ -51,31 +69,33 @@ type ColorChange struct {
 	Color Color
 	Pos   token.Pos
 	End   token.Pos
+	// Move links moved statement with its other location, it is set only for ColorMoved.
+	Move *Move
 }
 
 func NewColorChange(color Color, node ast.Node) ColorChange {
 	logrus.Debugln("NewColorChange:", color, node, node.Pos(), node.End()-1)
-	return ColorChange{color, node.Pos(), node.End() - 1}
+	return ColorChange{Color: color, Pos: node.Pos(), End: node.End() - 1}
 }
 
 type Coloring []ColorChange
 
-func colorMatches(matching []matching, mode Mode, callFunc string) (coloring Coloring) {
+func (c *context) colorMatches(matching []matching, mode Mode, callFunc string) (coloring Coloring) {
 	for _, match := range matching {
 		if match.next == nil {
 			logrus.Debugln(callFunc, "unmatched:", match.prev, reflect.TypeOf(match.prev))
 			coloring = append(coloring, NewColorChange(mode.ToColor(), match.prev))
 		} else {
-			coloring = append(coloring, diff(match.prev, match.next, mode)...)
+			coloring = append(coloring, c.diff(match.prev, match.next, mode)...)
 		}
 	}
 	return
 }
 
-func colorList(a, b []ast.Node, mode Mode, callFunc string) (coloring Coloring) {
+func (c *context) colorList(a, b []ast.Node, mode Mode, callFunc string) (coloring Coloring) {
 	min := util.IntMin(len(a), len(b))
 	for i := 0; i < min; i++ {
-		coloring = append(coloring, diff(a[i], b[i], mode)...)
+		coloring = append(coloring, c.diff(a[i], b[i], mode)...)
 	}
 	if len(a) > min {
 		for _, aNode := range a[min:] {
@@ -9,7 +9,12 @@ import (
 	"github.com/wookesh/gohist/util"
 )
 
-func compare(aNode, bNode ast.Node) (score float64) {
+// Similarity returns score in range [0, 1] describing how similar given nodes are.
+func Similarity(aNode, bNode ast.Node) float64 {
+	return plain.compare(aNode, bNode)
+}
+
+func (c *context) compare(aNode, bNode ast.Node) (score float64) {
 	defer func() { logrus.Debugln("compare:", "return:", score) }()
 	if aNode == nil {
 		if bNode == nil {
@@ -28,7 +33,7 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.DeclStmt:", a, bNode)
 		b, ok := bNode.(*ast.DeclStmt)
 		if ok {
-			score += compare(a.Decl, b.Decl)
+			score += c.compare(a.Decl, b.Decl)
 		}
 	case *ast.EmptyStmt:
 		logrus.Debugln("comapare:", "*ast.EmptyStmt:", a, bNode)
@@ -38,28 +43,29 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		}
 	case *ast.LabeledStmt:
 		logrus.Debugln("comapare:", "*ast.LabeledStmt:", a, bNode)
-		_, ok := bNode.(*ast.LabeledStmt)
+		b, ok := bNode.(*ast.LabeledStmt)
 		if ok {
-			logrus.Errorln("compare:", "unimplemented:", reflect.TypeOf(a))
+			score += c.compare(a.Label, b.Label) * (1 - 1/math.Phi)
+			score += c.compare(a.Stmt, b.Stmt) * (1 / math.Phi)
 		}
 	case *ast.ExprStmt:
 		logrus.Debugln("comapare:", "*ast.ExprStmt:", a, bNode)
 		b, ok := bNode.(*ast.ExprStmt)
 		if ok {
-			score += compare(a.X, b.X)
+			score += c.compare(a.X, b.X)
 		}
 	case *ast.SendStmt:
 		logrus.Debugln("comapare:", "*ast.SendStmt:", a, bNode)
 		b, ok := bNode.(*ast.SendStmt)
 		if ok {
-			score += compare(a.Chan, b.Chan) / 2
-			score += compare(a.Value, b.Value) / 2
+			score += c.compare(a.Chan, b.Chan) / 2
+			score += c.compare(a.Value, b.Value) / 2
 		}
 	case *ast.IncDecStmt:
 		logrus.Debugln("comapare:", "*ast.IncDecStmt:", a, bNode)
 		b, ok := bNode.(*ast.IncDecStmt)
 		if ok {
-			score += compare(a.X, b.X) * (1 / math.Phi)
+			score += c.compare(a.X, b.X) * (1 / math.Phi)
 			if a.Tok == b.Tok {
 				score += 1 - 1/math.Phi
 			}
@@ -70,11 +76,11 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		if ok {
 			minLhs := util.IntMin(len(a.Lhs), len(b.Lhs))
 			for i := 0; i < minLhs; i++ {
-				score += compare(a.Lhs[i], b.Lhs[i])
+				score += c.compare(a.Lhs[i], b.Lhs[i])
 			}
 			minRhs := util.IntMin(len(a.Rhs), len(b.Rhs))
 			for i := 0; i < minRhs; i++ {
-				score += compare(a.Rhs[i], b.Rhs[i])
+				score += c.compare(a.Rhs[i], b.Rhs[i])
 			}
 			score = score / float64(minRhs+minLhs)
 		}
@@ -82,18 +88,18 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.GoStmt:", a, bNode)
 		b, ok := bNode.(*ast.GoStmt)
 		if ok {
-			score += compare(a.Call, b.Call)
+			score += c.compare(a.Call, b.Call)
 		} else {
 			//b, ok := bNode.(*ast.CallExpr)
 			//if ok {
-			//	score += compare(a.Call, b) * (1 - 1/math.Phi)
+			//	score += c.compare(a.Call, b) * (1 - 1/math.Phi)
 			//}
 		}
 	case *ast.DeferStmt:
 		logrus.Debugln("comapare:", "*ast.DeferStmt:", a, bNode)
 		b, ok := bNode.(*ast.DeferStmt)
 		if ok {
-			score += compare(a.Call, b.Call)
+			score += c.compare(a.Call, b.Call)
 		}
 	case *ast.ReturnStmt:
 		logrus.Debugln("comapare:", "*ast.ReturnStmt:", a, bNode)
@@ -103,9 +109,9 @@ func compare(aNode, bNode ast.Node) (score float64) {
 				score = 1
 			} else {
 				max := util.IntMax(len(a.Results), len(b.Results))
-				for _, match := range matchExprs(a.Results, b.Results) {
+				for _, match := range c.matchExprs(a.Results, b.Results) {
 					if match.next != nil {
-						score += compare(match.prev, match.next) / float64(max)
+						score += c.compare(match.prev, match.next) / float64(max)
 					}
 				}
 			}
@@ -120,18 +126,22 @@ func compare(aNode, bNode ast.Node) (score float64) {
 			if a.Label != nil {
 				score = score / 2
 				if b.Label != nil {
-					score += compare(a.Label, b.Label) / 2
+					score += c.compare(a.Label, b.Label) / 2
 				}
 			}
 		}
 	case *ast.BlockStmt:
 		logrus.Debugln("comapare:", "*ast.BlockStmt:", a, bNode)
 		b, ok := bNode.(*ast.BlockStmt)
-		if ok {
+		if ok && (a == nil || b == nil) {
+			if a == nil && b == nil {
+				score += 1
+			}
+		} else if ok {
 			max := util.IntMax(len(a.List), len(b.List))
-			for _, match := range matchStmts(a.List, b.List) {
+			for _, match := range c.matchStmts(a.List, b.List) {
 				if match.next != nil {
-					score += compare(match.prev, match.next) / float64(max)
+					score += c.compare(match.prev, match.next) / float64(max)
 				}
 			}
 		}
@@ -142,13 +152,13 @@ func compare(aNode, bNode ast.Node) (score float64) {
 			parts := 2.0
 			if a.Init != nil {
 				parts++
-				score += compare(a.Init, b.Init)
+				score += c.compare(a.Init, b.Init)
 			}
-			score += compare(a.Cond, b.Cond)
-			score += compare(a.Body, b.Body)
+			score += c.compare(a.Cond, b.Cond)
+			score += c.compare(a.Body, b.Body)
 			if a.Else != nil {
 				parts++
-				score += compare(a.Else, b.Else)
+				score += c.compare(a.Else, b.Else)
 			}
 			score = score / parts
 		}
@@ -156,25 +166,25 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.SwitchStmt:", a, bNode)
 		b, ok := bNode.(*ast.SwitchStmt)
 		if ok {
-			score += compare(a.Init, b.Init) * (1 / math.Phi)
-			score += compare(a.Body, b.Body) * (1 - 1/math.Phi)
+			score += c.compare(a.Init, b.Init) * (1 / math.Phi)
+			score += c.compare(a.Body, b.Body) * (1 - 1/math.Phi)
 		}
 	case *ast.TypeSwitchStmt:
 		logrus.Debugln("comapare:", "*ast.TypeSwitchStmt:", a, bNode)
 		b, ok := bNode.(*ast.TypeSwitchStmt)
 		if ok {
-			score += compare(a.Assign, b.Assign) * (1 - 1/math.Phi)
+			score += c.compare(a.Assign, b.Assign) * (1 - 1/math.Phi)
 			if a.Init != nil {
-				score += compare(a.Init, b.Init) * (1 - 1/math.Phi)
+				score += c.compare(a.Init, b.Init) * (1 - 1/math.Phi)
 				score = score / 2
 			}
-			score += compare(a.Body, b.Body) * (1 / math.Phi)
+			score += c.compare(a.Body, b.Body) * (1 / math.Phi)
 		}
 	case *ast.SelectStmt:
 		logrus.Debugln("comapare:", "*ast.SelectStmt:", a, bNode)
 		b, ok := bNode.(*ast.SelectStmt)
 		if ok {
-			score += compare(a.Body, b.Body)
+			score += c.compare(a.Body, b.Body)
 		}
 	case *ast.ForStmt:
 		logrus.Debugln("comapare:", "*ast.ForStmt:", a, bNode)
@@ -183,20 +193,20 @@ func compare(aNode, bNode ast.Node) (score float64) {
 			children := 0
 			if a.Init != nil {
 				children++
-				score += compare(a.Init, b.Init)
+				score += c.compare(a.Init, b.Init)
 			}
 			if a.Cond != nil {
 				children++
-				score += compare(a.Cond, b.Cond)
+				score += c.compare(a.Cond, b.Cond)
 			}
 			if a.Post != nil {
 				children++
-				score += compare(a.Post, b.Post)
+				score += c.compare(a.Post, b.Post)
 			}
 			if children > 0 {
 				score = (score * (1 - 1/math.Phi)) / float64(children)
 			}
-			score += compare(a.Body, b.Body) / math.Phi
+			score += c.compare(a.Body, b.Body) / math.Phi
 		}
 	case *ast.RangeStmt:
 		logrus.Debugln("comapare:", "*ast.RangeStmt:", a, bNode)
@@ -205,21 +215,21 @@ func compare(aNode, bNode ast.Node) (score float64) {
 			children := 1
 			if a.Key != nil {
 				children++
-				score += compare(a.Key, b.Key)
+				score += c.compare(a.Key, b.Key)
 			}
 			if a.Value != nil {
 				children++
-				score += compare(a.Value, b.Value)
+				score += c.compare(a.Value, b.Value)
 			}
-			score += compare(a.X, b.X)
+			score += c.compare(a.X, b.X)
 			score = (score * (1 - 1/math.Phi)) / float64(children)
-			score += compare(a.Body, b.Body) / math.Phi
+			score += c.compare(a.Body, b.Body) / math.Phi
 		}
 	case *ast.Ident:
 		logrus.Debugln("comapare:", "*ast.Ident:", a, bNode)
 		b, ok := bNode.(*ast.Ident)
 		if ok {
-			if a.Name == b.Name {
+			if c.similarIdent(a, b) {
 				score += 1
 			}
 		}
@@ -228,19 +238,19 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		b, ok := bNode.(*ast.CallExpr)
 		if ok {
 			total := util.IntMax(len(a.Args), len(b.Args))
-			for _, match := range matchExprs(a.Args, b.Args) {
+			for _, match := range c.matchExprs(a.Args, b.Args) {
 				if match.next != nil {
-					score += compare(match.prev, match.next) / float64(total)
+					score += c.compare(match.prev, match.next) / float64(total)
 				}
 			}
 			score = score * (1 / math.Phi)
-			score += compare(a.Fun, b.Fun) * (1 - (1 / math.Phi))
+			score += c.compare(a.Fun, b.Fun) * (1 - (1 / math.Phi))
 		}
 	case *ast.StarExpr:
 		logrus.Debugln("comapare:", "*ast.StarExpr:", a, bNode)
 		b, ok := bNode.(*ast.StarExpr)
 		if ok {
-			score += compare(a.X, b.X)
+			score += c.compare(a.X, b.X)
 		}
 	case *ast.CaseClause:
 		logrus.Debugln("comapare:", "*ast.CaseClause:", a, bNode)
@@ -250,9 +260,9 @@ func compare(aNode, bNode ast.Node) (score float64) {
 			if len(a.List) == 0 && len(b.List) == 0 {
 				score += 1
 			} else {
-				for _, match := range matchExprs(a.List, b.List) {
+				for _, match := range c.matchExprs(a.List, b.List) {
 					if match.next != nil {
-						score += compare(match.prev, match.next)
+						score += c.compare(match.prev, match.next)
 					}
 				}
 				score = score / float64(util.IntMax(len(a.List), len(b.List)))
@@ -263,7 +273,7 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.SelectorExpr:", a, bNode)
 		b, ok := bNode.(*ast.SelectorExpr)
 		if ok {
-			score = compare(a.X, b.X) * (1 / math.Phi)
+			score = c.compare(a.X, b.X) * (1 / math.Phi)
 			if a.Sel.Name == b.Sel.Name {
 				score += 1 - (1 / math.Phi)
 			}
@@ -283,40 +293,46 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.TypeAssertExpr:", a, bNode)
 		b, ok := bNode.(*ast.TypeAssertExpr)
 		if ok {
-			score += compare(a.X, b.X)
+			score += c.compare(a.X, b.X)
 			if a.Type != nil || b.Type != nil {
-				score = (score + compare(a.Type, b.Type)) / 2
+				score = (score + c.compare(a.Type, b.Type)) / 2
 			}
 		}
 	case *ast.CompositeLit:
 		logrus.Debugln("comapare:", "*ast.CompositeLit:", a, bNode)
 		b, ok := bNode.(*ast.CompositeLit)
 		if ok {
-			score += compare(a.Type, b.Type)
+			score += c.compare(a.Type, b.Type)
 		}
 	case *ast.Field:
 		logrus.Debugln("comapare:", "*ast.Field:", a, bNode)
 		b, ok := bNode.(*ast.Field)
 		if ok {
-			score += compare(a.Type, b.Type)
-
+			if len(a.Names) > 0 && len(b.Names) > 0 {
+				score += c.compare(a.Type, b.Type) * (1 / math.Phi)
+				if a.Names[0].Name == b.Names[0].Name {
+					score += 1 - 1/math.Phi
+				}
+			} else {
+				score += c.compare(a.Type, b.Type)
+			}
 		}
 	case *ast.BinaryExpr:
 		logrus.Debugln("comapare:", "*ast.BinaryExpr:", a, bNode)
 		b, ok := bNode.(*ast.BinaryExpr)
 		if ok {
 			if a.Op == b.Op {
-				score += 1 / 3
+				score += 1.0 / 3
 			}
-			score += (compare(a.X, b.X) + compare(a.Y, b.Y)) / 3
+			score += (c.compare(a.X, b.X) + c.compare(a.Y, b.Y)) / 3
 		}
 	case *ast.ArrayType:
 		logrus.Debugln("comapare:", "*ast.ArrayType:", a, bNode)
 		b, ok := bNode.(*ast.ArrayType)
 		if ok {
-			score += compare(a.Elt, b.Elt) * (1 / math.Phi)
+			score += c.compare(a.Elt, b.Elt) * (1 / math.Phi)
 			if a.Len != nil {
-				score += compare(a.Len, b.Len) * (1 - (1 / math.Phi))
+				score += c.compare(a.Len, b.Len) * (1 - (1 / math.Phi))
 			} else {
 				if b.Len == nil {
 					score += 1 - 1/math.Phi
@@ -327,15 +343,21 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.FuncLit:", a, bNode)
 		b, ok := bNode.(*ast.FuncLit)
 		if ok {
-			score += compare(a.Type, b.Type) * (1 - 1/math.Phi)
-			score += compare(a.Body, b.Body) * (1 / math.Phi)
+			score += c.compare(a.Type, b.Type) * (1 - 1/math.Phi)
+			score += c.compare(a.Body, b.Body) * (1 / math.Phi)
 		}
 	case *ast.FuncType:
 		logrus.Debugln("comapare:", "*ast.FuncType:", a, bNode)
 		b, ok := bNode.(*ast.FuncType)
 		if ok {
-			score += compare(a.Params, b.Params) / 2
-			score += compare(a.Results, b.Results) / 2
+			if a.TypeParams == nil && b.TypeParams == nil {
+				score += c.compare(a.Params, b.Params) / 2
+				score += c.compare(a.Results, b.Results) / 2
+			} else {
+				score += c.compare(a.TypeParams, b.TypeParams) / 3
+				score += c.compare(a.Params, b.Params) / 3
+				score += c.compare(a.Results, b.Results) / 3
+			}
 		}
 	case *ast.FieldList:
 		logrus.Debugln("comapare:", "*ast.FieldList:", a, bNode)
@@ -350,35 +372,36 @@ func compare(aNode, bNode ast.Node) (score float64) {
 				if max == 0 {
 					score += 1
 				}
-				for _, match := range matchFields(a.List, b.List) {
+				for _, match := range c.matchFields(a.List, b.List) {
 					if match.next != nil {
 						score += 1 / float64(max)
 					}
 				}
 			}
 		}
-	case *ast.IndexExpr:
+	case *ast.IndexExpr, *ast.IndexListExpr:
 		logrus.Debugln("comapare:", "*ast.IndexExpr:", a, bNode)
-		b, ok := bNode.(*ast.IndexExpr)
+		aX, aIndices, _ := indexed(a)
+		bX, bIndices, ok := indexed(bNode)
 		if ok {
-			score += compare(a.X, b.X) * 1 / math.Phi
-			score += compare(a.Index, b.Index) * (1 - 1/math.Phi)
+			score += c.compare(aX, bX) * 1 / math.Phi
+			score += c.compareExprs(aIndices, bIndices) * (1 - 1/math.Phi)
 		}
 	case *ast.MapType:
 		logrus.Debugln("comapare:", "*ast.MapType:", a, bNode)
 		b, ok := bNode.(*ast.MapType)
 		if ok {
-			score += compare(a.Key, b.Key) / 2
-			score += compare(a.Value, b.Value) / 2
+			score += c.compare(a.Key, b.Key) / 2
+			score += c.compare(a.Value, b.Value) / 2
 		}
 	case *ast.GenDecl:
 		logrus.Debugln("comapare:", "*ast.GenDecl:", a, bNode)
 		b, ok := bNode.(*ast.GenDecl)
 		if ok {
 			max := util.IntMax(len(a.Specs), len(b.Specs))
-			for _, match := range matchSpecs(a.Specs, b.Specs) {
+			for _, match := range c.matchSpecs(a.Specs, b.Specs) {
 				if match.next != nil {
-					score += compare(match.prev, match.next) / float64(max)
+					score += c.compare(match.prev, match.next) / float64(max)
 				}
 			}
 		}
@@ -387,9 +410,9 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		b, ok := bNode.(*ast.ValueSpec)
 		if ok {
 			max := util.IntMax(len(a.Names), len(b.Names))
-			for _, match := range matchIdents(a.Names, b.Names) {
+			for _, match := range c.matchIdents(a.Names, b.Names) {
 				if match.next != nil {
-					score += compare(match.prev, match.next) / float64(max)
+					score += c.compare(match.prev, match.next) / float64(max)
 				}
 			}
 		}
@@ -397,7 +420,7 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.ParenExpr:", a, bNode)
 		b, ok := bNode.(*ast.ParenExpr)
 		if ok {
-			score = compare(a.X, b.X)
+			score = c.compare(a.X, b.X)
 		}
 	case *ast.SliceExpr:
 		logrus.Debugln("comapare:", "*ast.SliceExpr:", a, bNode)
@@ -406,18 +429,18 @@ func compare(aNode, bNode ast.Node) (score float64) {
 			parts := 0
 			if a.Low != nil {
 				parts++
-				score += compare(a.Low, b.Low)
+				score += c.compare(a.Low, b.Low)
 			}
 			if a.High != nil {
 				parts++
-				score += compare(a.High, b.High)
+				score += c.compare(a.High, b.High)
 			}
 			if a.Max != nil {
 				parts++
-				score += compare(a.Max, b.Max)
+				score += c.compare(a.Max, b.Max)
 			}
 			score = (score / float64(parts)) * (1 - 1/math.Phi)
-			score += compare(a.X, b.X) * (1 / math.Phi)
+			score += c.compare(a.X, b.X) * (1 / math.Phi)
 		}
 	case *ast.UnaryExpr:
 		logrus.Debugln("comapare:", "*ast.UnaryExpr:", a, bNode)
@@ -426,13 +449,13 @@ func compare(aNode, bNode ast.Node) (score float64) {
 			if a.Op == b.Op {
 				score += 1 - 1/math.Phi
 			}
-			score += compare(a.X, b.X) * (1 / math.Phi)
+			score += c.compare(a.X, b.X) * (1 / math.Phi)
 		}
 	case *ast.KeyValueExpr:
 		logrus.Debugln("comapare:", "*ast.KeyValueExpr:", a, bNode)
 		b, ok := bNode.(*ast.KeyValueExpr)
 		if ok {
-			score += (compare(a.Key, b.Key) + compare(a.Value, b.Value)) / 2
+			score += (c.compare(a.Key, b.Key) + c.compare(a.Value, b.Value)) / 2
 		}
 	case *ast.InterfaceType:
 		logrus.Debugln("comapare:", "*ast.InterfaceType:", a, bNode)
@@ -444,7 +467,7 @@ func compare(aNode, bNode ast.Node) (score float64) {
 				}
 			} else {
 				if b.Methods != nil {
-					score += compare(a.Methods, b.Methods)
+					score += c.compare(a.Methods, b.Methods)
 				}
 			}
 		}
@@ -452,7 +475,7 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.ChanType:", a, bNode)
 		b, ok := bNode.(*ast.ChanType)
 		if ok {
-			score += compare(a.Value, b.Value) * 1 / math.Phi
+			score += c.compare(a.Value, b.Value) * 1 / math.Phi
 			if a.Dir == b.Dir {
 				score += 1 - 1/math.Phi
 			}
@@ -461,22 +484,74 @@ func compare(aNode, bNode ast.Node) (score float64) {
 		logrus.Debugln("comapare:", "*ast.CommClause:", a, bNode)
 		b, ok := bNode.(*ast.CommClause)
 		if ok {
-			score += compare(a.Comm, b.Comm) * 1 / math.Phi
+			score += c.compare(a.Comm, b.Comm) * 1 / math.Phi
 			max := float64(util.IntMax(len(a.Body), len(b.Body)))
-			for _, match := range matchStmts(a.Body, b.Body) {
+			for _, match := range c.matchStmts(a.Body, b.Body) {
 				if match.next != nil {
-					score += compare(match.prev, match.prev) * (1 - 1/math.Phi) / max
+					score += c.compare(match.prev, match.prev) * (1 - 1/math.Phi) / max
 				}
 			}
 		}
+	case *ast.FuncDecl:
+		logrus.Debugln("comapare:", "*ast.FuncDecl:", a, bNode)
+		b, ok := bNode.(*ast.FuncDecl)
+		if ok {
+			// names are skipped, so renamed functions are still similar
+			score += (c.compare(a.Recv, b.Recv) + c.compare(a.Type, b.Type)) / 2 * (1 - 1/math.Phi)
+			score += c.compare(a.Body, b.Body) * (1 / math.Phi)
+		}
+	case *ast.TypeSpec:
+		logrus.Debugln("comapare:", "*ast.TypeSpec:", a, bNode)
+		b, ok := bNode.(*ast.TypeSpec)
+		if ok {
+			if a.TypeParams == nil && b.TypeParams == nil {
+				score += c.compare(a.Type, b.Type) * (1 / math.Phi)
+			} else {
+				score += (c.compare(a.TypeParams, b.TypeParams) + c.compare(a.Type, b.Type)) / 2 * (1 / math.Phi)
+			}
+			if a.Name.Name == b.Name.Name {
+				score += 1 - 1/math.Phi
+			}
+		}
+	case *ast.StructType:
+		logrus.Debugln("comapare:", "*ast.StructType:", a, bNode)
+		b, ok := bNode.(*ast.StructType)
+		if ok {
+			score += c.compare(a.Fields, b.Fields)
+		}
 	case *ast.Ellipsis:
 		logrus.Debugln("comapare:", "*ast.Ellipsis:", a, bNode)
 		b, ok := bNode.(*ast.Ellipsis)
 		if ok {
-			score += compare(a.Elt, b.Elt)
+			score += c.compare(a.Elt, b.Elt)
 		}
 	default:
 		logrus.Errorln("compare:", "unimplemented case: ", reflect.TypeOf(a))
 	}
 	return
 }
+
+// indexed returns indexed expression and its indices, so single and multiple type arguments can be compared.
+func indexed(node ast.Node) (ast.Expr, []ast.Expr, bool) {
+	switch n := node.(type) {
+	case *ast.IndexExpr:
+		return n.X, []ast.Expr{n.Index}, true
+	case *ast.IndexListExpr:
+		return n.X, n.Indices, true
+	default:
+		return nil, nil, false
+	}
+}
+
+func (c *context) compareExprs(a, b []ast.Expr) (score float64) {
+	total := util.IntMax(len(a), len(b))
+	if total == 0 {
+		return 1
+	}
+	for _, match := range c.matchExprs(a, b) {
+		if match.next != nil {
+			score += c.compare(match.prev, match.next) / float64(total)
+		}
+	}
+	return
+}
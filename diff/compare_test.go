@@ -0,0 +1,100 @@
+package diff
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func parseDecl(t testing.TB, src string) ast.Decl {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Decls[0]
+}
+
+// colored returns fragments of src covered by coloring, src has to be parsed with parseDecl.
+func colored(src string, coloring Coloring) (result []string) {
+	src = "package p\n" + src
+	for _, change := range coloring {
+		result = append(result, src[change.Pos-1:change.End])
+	}
+	return
+}
+
+// field returns the first field of struct type declared in src.
+func field(t *testing.T, src string) *ast.Field {
+	spec := parseDecl(t, src).(*ast.GenDecl).Specs[0].(*ast.TypeSpec)
+	return spec.Type.(*ast.StructType).Fields.List[0]
+}
+
+func TestSimilarityField(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		score float64
+	}{
+		{"same", "type T struct{ x int }", "type T struct{ x int }", 1},
+		{"renamed", "type T struct{ x int }", "type T struct{ y int }", 1 / math.Phi},
+		{"retyped", "type T struct{ x int }", "type T struct{ x string }", 1 - 1/math.Phi},
+		{"renamed and retyped", "type T struct{ x int }", "type T struct{ y string }", 0},
+		{"embedded", "type T struct{ io.Reader }", "type T struct{ io.Reader }", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if score := Similarity(field(t, tt.a), field(t, tt.b)); math.Abs(score-tt.score) > 1e-9 {
+				t.Errorf("Similarity() = %v, want %v", score, tt.score)
+			}
+		})
+	}
+	// embedded fields have no names, so only their types are compared
+	a, b := field(t, "type T struct{ io.Reader }"), field(t, "type T struct{ io.Writer }")
+	if score, want := Similarity(a, b), Similarity(a.Type, b.Type); score != want || score == 1 {
+		t.Errorf("Similarity() of embedded fields = %v, want %v", score, want)
+	}
+}
+
+func TestSimilarityTypeSpec(t *testing.T) {
+	spec := func(src string) ast.Node { return parseDecl(t, src).(*ast.GenDecl).Specs[0] }
+	same := Similarity(spec("type T struct{ x int; y string }"), spec("type T struct{ x int; y string }"))
+	renamed := Similarity(spec("type T struct{ x int; y string }"), spec("type U struct{ x int; y string }"))
+	fieldChanged := Similarity(spec("type T struct{ x int; y string }"), spec("type T struct{ x int; z bool }"))
+	other := Similarity(spec("type T struct{ x int; y string }"), spec("type T int"))
+	if same != 1 || math.Abs(renamed-1/math.Phi) > 1e-9 {
+		t.Errorf("Similarity() of same = %v, renamed = %v, want 1 and %v", same, renamed, 1/math.Phi)
+	}
+	if !(fieldChanged < same && fieldChanged > other) {
+		t.Errorf("Similarity() with changed field = %v, want between %v and %v", fieldChanged, other, same)
+	}
+}
+
+func TestDiffType(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		old, new []string
+	}{
+		{"same", "type T struct{ x int }", "type T struct{ x int }", nil, nil},
+		{"field added", "type T struct{ x int }", "type T struct{ x int; y string }", nil, []string{"y string"}},
+		{"field retyped", "type T struct{ x int }", "type T struct{ x int64 }", []string{"int"}, []string{"int64"}},
+		{"tag", "type T struct{ x int `json:\"x\"` }", "type T struct{ x int `json:\"y\"` }", []string{"`json:\"x\"`"}, []string{"`json:\"y\"`"}},
+		{"tag added", "type T struct{ x int }", "type T struct{ x int `json:\"x\"` }", nil, []string{"`json:\"x\"`"}},
+		{"alias", "type T = int", "type T int", []string{"T = int"}, []string{"T int"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parseDecl(t, tt.a), parseDecl(t, tt.b)
+			if old := colored(tt.a, Diff(a, b, ModeOld)); !reflect.DeepEqual(old, tt.old) {
+				t.Errorf("Diff(ModeOld) = %q, want %q", old, tt.old)
+			}
+			if new := colored(tt.b, Diff(b, a, ModeNew)); !reflect.DeepEqual(new, tt.new) {
+				t.Errorf("Diff(ModeNew) = %q, want %q", new, tt.new)
+			}
+		})
+	}
+}
@@ -8,48 +8,168 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// context tracks local variables of two compared nodes and how they were renamed from one to another, nil context
+// compares identifiers by name.
 type context struct {
 	a nodeContext
 	b nodeContext
+	// renamed lists declarations of a in order they were bound, so bindings of failed comparison can be undone
+	renamed []token.Pos
 }
 
 type nodeContext struct {
-	vars       vars
-	globalVars vars
+	vars vars
+	// bound maps declaration of local to declaration of local it was matched with in the other node
+	bound map[token.Pos]token.Pos
 }
 
-type vars map[string][]token.Pos
+// vars maps identifiers referring to locals to positions where they are declared.
+type vars map[*ast.Ident]token.Pos
+
+// plain compares identifiers by name.
+var plain *context
+
+func newContext(a, b ast.Node) *context {
+	return &context{
+		a: nodeContext{vars: locals(a), bound: make(map[token.Pos]token.Pos)},
+		b: nodeContext{vars: locals(b), bound: make(map[token.Pos]token.Pos)},
+	}
+}
+
+// reversed returns context comparing b with a, bindings are shared with c.
+func (c *context) reversed() *context {
+	if c == nil {
+		return nil
+	}
+	return &context{a: c.b, b: c.a}
+}
+
+// sameIdent reports whether x of a and y of b have the same name or refer to locals matched with each other, locals
+// which are not matched yet get matched.
+func (c *context) sameIdent(x, y *ast.Ident) bool {
+	if c == nil {
+		return x.Name == y.Name
+	}
+	xDecl, xOk := c.a.vars[x]
+	yDecl, yOk := c.b.vars[y]
+	if !xOk || !yOk {
+		return !xOk && !yOk && x.Name == y.Name
+	}
+	to, xBound := c.a.bound[xDecl]
+	from, yBound := c.b.bound[yDecl]
+	if xBound || yBound {
+		return to == yDecl && from == xDecl
+	}
+	c.a.bound[xDecl], c.b.bound[yDecl] = yDecl, xDecl
+	c.renamed = append(c.renamed, xDecl)
+	return true
+}
+
+// similarIdent is sameIdent which does not match locals, it is used to score candidates for matching.
+func (c *context) similarIdent(x, y *ast.Ident) bool {
+	if c == nil {
+		return x.Name == y.Name
+	}
+	xDecl, xOk := c.a.vars[x]
+	yDecl, yOk := c.b.vars[y]
+	if !xOk || !yOk {
+		return !xOk && !yOk && x.Name == y.Name
+	}
+	to, xBound := c.a.bound[xDecl]
+	from, yBound := c.b.bound[yDecl]
+	return !xBound && !yBound || to == yDecl && from == xDecl
+}
+
+func (c *context) mark() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.renamed)
+}
+
+// unbind undoes bindings made after mark.
+func (c *context) unbind(mark int) {
+	if c == nil {
+		return
+	}
+	for _, x := range c.renamed[mark:] {
+		delete(c.b.bound, c.a.bound[x])
+		delete(c.a.bound, x)
+	}
+	c.renamed = c.renamed[:mark]
+}
+
+// IsSameAlpha reports whether a and b are the same up to consistent renaming of local variables and parameters.
+func IsSameAlpha(a, b ast.Node) bool {
+	return newContext(a, b).same(a, b)
+}
+
+// DiffAlpha colors changes like Diff, but local variables and parameters consistently renamed are not colored.
+func DiffAlpha(a, b ast.Node, mode Mode) Coloring {
+	return newContext(a, b).colorChanges(a, b, mode)
+}
 
 func Diff(a, b ast.Node, mode Mode) Coloring {
-	logrus.Debugln("Diff:", mode, "\n")
+	return plain.colorChanges(a, b, mode)
+}
+
+// DiffPair colors old compared with new and new compared with old like two calls of Diff, but both versions are
+// diffed only once.
+func DiffPair(old, new ast.Node) (left, right Coloring) {
+	return plain.colorPair(old, new)
+}
+
+// DiffAlphaPair is DiffPair which does not color consistently renamed local variables and parameters.
+func DiffAlphaPair(old, new ast.Node) (left, right Coloring) {
+	return newContext(old, new).colorPair(old, new)
+}
+
+func (c *context) colorChanges(a, b ast.Node, mode Mode) Coloring {
+	logrus.Debugln("Diff:", mode)
 	if mode == ModeNew && a == nil {
 		return Coloring{NewColorChange(mode.ToColor(), b)}
 	}
 	if mode == ModeOld && b == nil {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	return diff(a, b, mode)
+	coloring := c.diff(a, b, mode)
+	if a == nil || b == nil {
+		return coloring
+	}
+	return c.detectMoves(a, b, coloring, func() Coloring { return c.reversed().diff(b, a, mode.opposite()) }, mode)
 }
 
-func diff(aNode, b ast.Node, mode Mode) (coloring Coloring) {
+// colorPair colors changes of old and new, coloring of each side is reused to detect moves on the other one.
+func (c *context) colorPair(old, new ast.Node) (left, right Coloring) {
+	if old == nil || new == nil {
+		return c.colorChanges(old, new, ModeOld), c.reversed().colorChanges(new, old, ModeNew)
+	}
+	left, right = c.diff(old, new, ModeOld), c.reversed().diff(new, old, ModeNew)
+	return c.detectMoves(old, new, left, func() Coloring { return right }, ModeOld),
+		c.reversed().detectMoves(new, old, right, func() Coloring { return left }, ModeNew)
+}
+
+func (c *context) diff(aNode, b ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diff:", aNode, b)
 	if aNode == nil {
 		return
 	}
 	switch a := aNode.(type) {
 	case ast.Decl:
-		coloring = diffDecl(a, b, mode)
+		coloring = c.diffDecl(a, b, mode)
 	case ast.Expr:
-		coloring = diffExpr(a, b, mode)
+		coloring = c.diffExpr(a, b, mode)
 	case ast.Stmt:
-		coloring = diffStmt(a, b, mode)
+		coloring = c.diffStmt(a, b, mode)
 	// non interface nodes:
 	case *ast.Field:
-		coloring = diffField(a, b, mode)
+		coloring = c.diffField(a, b, mode)
 	case *ast.FieldList:
-		coloring = diffFieldList(a, b, mode)
+		coloring = c.diffFieldList(a, b, mode)
 	case *ast.ValueSpec:
-		coloring = diffValueSpec(a, b, mode)
+		coloring = c.diffValueSpec(a, b, mode)
+	case *ast.TypeSpec:
+		coloring = c.diffTypeSpec(a, b, mode)
 	default:
 		logrus.Errorln("diff:", "not implemented case", reflect.TypeOf(a))
 		coloring = Coloring{NewColorChange(mode.ToColor(), a)}
@@ -58,7 +178,7 @@ func diff(aNode, b ast.Node, mode Mode) (coloring Coloring) {
 	return
 }
 
-func diffFieldList(a *ast.FieldList, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffFieldList(a *ast.FieldList, bNode ast.Node, mode Mode) (coloring Coloring) {
 	b, ok := bNode.(*ast.FieldList)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
@@ -69,36 +189,55 @@ func diffFieldList(a *ast.FieldList, bNode ast.Node, mode Mode) (coloring Colori
 	if b == nil {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	for _, match := range matchFields(a.List, b.List) {
+	for _, match := range c.matchFields(a.List, b.List) {
 		if match.next == nil {
 			coloring = append(coloring, NewColorChange(mode.ToColor(), match.prev))
 		} else {
-			coloring = append(coloring, diff(match.prev, match.next, mode)...)
+			coloring = append(coloring, c.diff(match.prev, match.next, mode)...)
 		}
 	}
 
 	return
 }
 
-func diffField(a *ast.Field, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffField(a *ast.Field, bNode ast.Node, mode Mode) (coloring Coloring) {
 	b, ok := bNode.(*ast.Field)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
 
-	coloring = append(coloring, colorMatches(matchIdents(a.Names, b.Names), mode, "diffField")...)
-	coloring = append(coloring, diff(a.Type, b.Type, mode)...)
+	coloring = append(coloring, c.colorMatches(c.matchIdents(a.Names, b.Names), mode, "diffField")...)
+	coloring = append(coloring, c.diff(a.Type, b.Type, mode)...)
+	if a.Tag != nil {
+		if b.Tag == nil {
+			coloring = append(coloring, NewColorChange(mode.ToColor(), a.Tag))
+		} else {
+			coloring = append(coloring, c.diff(a.Tag, b.Tag, mode)...)
+		}
+	}
 	return
 }
 
-func diffValueSpec(a *ast.ValueSpec, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffValueSpec(a *ast.ValueSpec, bNode ast.Node, mode Mode) (coloring Coloring) {
 	b, ok := bNode.(*ast.ValueSpec)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
 
-	coloring = append(coloring, colorMatches(matchIdents(a.Names, b.Names), mode, "diffValueSpec")...)
-	coloring = append(coloring, colorMatches(matchExprs(a.Values, b.Values), mode, "diffValueSpec")...)
-	coloring = append(coloring, diff(a.Type, b.Type, mode)...)
+	coloring = append(coloring, c.colorMatches(c.matchIdents(a.Names, b.Names), mode, "diffValueSpec")...)
+	coloring = append(coloring, c.colorMatches(c.matchExprs(a.Values, b.Values), mode, "diffValueSpec")...)
+	coloring = append(coloring, c.diff(a.Type, b.Type, mode)...)
+	return
+}
+
+func (c *context) diffTypeSpec(a *ast.TypeSpec, bNode ast.Node, mode Mode) (coloring Coloring) {
+	b, ok := bNode.(*ast.TypeSpec)
+	if !ok || a.Assign.IsValid() != b.Assign.IsValid() {
+		return Coloring{NewColorChange(mode.ToColor(), a)}
+	}
+
+	coloring = append(coloring, c.diff(a.Name, b.Name, mode)...)
+	coloring = append(coloring, c.diff(a.TypeParams, b.TypeParams, mode)...)
+	coloring = append(coloring, c.diff(a.Type, b.Type, mode)...)
 	return
 }
@@ -7,24 +7,23 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func diffDecl(aDecl ast.Decl, bDecl ast.Node, mode Mode) Coloring {
+func (c *context) diffDecl(aDecl ast.Decl, bDecl ast.Node, mode Mode) Coloring {
 	b, ok := bDecl.(ast.Decl)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), aDecl)}
 	}
 	switch a := aDecl.(type) {
 	case *ast.FuncDecl:
-		return diffFuncDecl(a, b, mode)
+		return c.diffFuncDecl(a, b, mode)
 	case *ast.GenDecl:
-		return diffGenDecl(a, b, mode)
+		return c.diffGenDecl(a, b, mode)
 	default:
 		logrus.Errorln("diffDecl:", "unimplemented case:", reflect.TypeOf(a))
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	return nil
 }
 
-func diffFuncDecl(a *ast.FuncDecl, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffFuncDecl(a *ast.FuncDecl, bNode ast.Node, mode Mode) (coloring Coloring) {
 	b, ok := bNode.(*ast.FuncDecl)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
@@ -36,14 +35,15 @@ func diffFuncDecl(a *ast.FuncDecl, bNode ast.Node, mode Mode) (coloring Coloring
 			return Coloring{NewColorChange(mode.ToColor(), a)}
 		}
 	}
-	coloring = append(coloring, diff(a.Type, b.Type, mode)...)
-	coloring = append(coloring, diff(a.Name, b.Name, mode)...)
-	coloring = append(coloring, diff(a.Body, b.Body, mode)...)
+	coloring = append(coloring, c.diff(a.Recv, b.Recv, mode)...)
+	coloring = append(coloring, c.diff(a.Type, b.Type, mode)...)
+	coloring = append(coloring, c.diff(a.Name, b.Name, mode)...)
+	coloring = append(coloring, c.diff(a.Body, b.Body, mode)...)
 
 	return
 }
 
-func diffGenDecl(a *ast.GenDecl, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffGenDecl(a *ast.GenDecl, bNode ast.Node, mode Mode) (coloring Coloring) {
 	b, ok := bNode.(*ast.GenDecl)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
@@ -58,6 +58,6 @@ func diffGenDecl(a *ast.GenDecl, bNode ast.Node, mode Mode) (coloring Coloring)
 	if a.Tok != b.Tok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, colorMatches(matchSpecs(a.Specs, b.Specs), mode, "diffGenDecl")...)
+	coloring = append(coloring, c.colorMatches(c.matchSpecs(a.Specs, b.Specs), mode, "diffGenDecl")...)
 	return
 }
@@ -7,197 +7,209 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func diffStmt(aStmt ast.Stmt, bNode ast.Node, mode Mode) Coloring {
+func (c *context) diffStmt(aStmt ast.Stmt, bNode ast.Node, mode Mode) Coloring {
 	b, ok := bNode.(ast.Stmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), aStmt)}
 	}
 	switch a := aStmt.(type) {
 	case *ast.AssignStmt:
-		return diffAssignStmt(a, b, mode)
+		return c.diffAssignStmt(a, b, mode)
 	case *ast.BlockStmt:
-		return diffBlockStmt(a, b, mode)
+		return c.diffBlockStmt(a, b, mode)
 	case *ast.BranchStmt:
-		return diffBranchStmt(a, b, mode)
+		return c.diffBranchStmt(a, b, mode)
 	case *ast.CaseClause:
-		return diffCaseClause(a, b, mode)
+		return c.diffCaseClause(a, b, mode)
 	case *ast.CommClause:
-		return diffCommClause(a, b, mode)
+		return c.diffCommClause(a, b, mode)
 	case *ast.DeclStmt:
-		return diffDeclStmt(a, b, mode)
+		return c.diffDeclStmt(a, b, mode)
 	case *ast.DeferStmt:
-		return diffDeferStmt(a, b, mode)
+		return c.diffDeferStmt(a, b, mode)
 	case *ast.ExprStmt:
-		return diffExprStmt(a, b, mode)
+		return c.diffExprStmt(a, b, mode)
 	case *ast.ForStmt:
-		return diffForStmt(a, b, mode)
+		return c.diffForStmt(a, b, mode)
 	case *ast.GoStmt:
-		return diffGoStmt(a, b, mode)
+		return c.diffGoStmt(a, b, mode)
 	case *ast.IfStmt:
-		return diffIfStmt(a, b, mode)
+		return c.diffIfStmt(a, b, mode)
 	case *ast.IncDecStmt:
-		return diffIncDecStmt(a, b, mode)
+		return c.diffIncDecStmt(a, b, mode)
+	case *ast.LabeledStmt:
+		return c.diffLabeledStmt(a, b, mode)
 	case *ast.RangeStmt:
-		return diffRangeStmt(a, b, mode)
+		return c.diffRangeStmt(a, b, mode)
 	case *ast.ReturnStmt:
-		return diffReturnStmt(a, b, mode)
+		return c.diffReturnStmt(a, b, mode)
 	case *ast.SelectStmt:
-		return diffSelectStmt(a, b, mode)
+		return c.diffSelectStmt(a, b, mode)
 	case *ast.SendStmt:
-		return diffSendStmt(a, b, mode)
+		return c.diffSendStmt(a, b, mode)
 	case *ast.SwitchStmt:
-		return diffSwitchStmt(a, b, mode)
+		return c.diffSwitchStmt(a, b, mode)
 	case *ast.TypeSwitchStmt:
-		return diffTypeSwitchStmt(a, b, mode)
+		return c.diffTypeSwitchStmt(a, b, mode)
 	default:
 		logrus.Errorln("diffStmt:", "not implemented case", reflect.TypeOf(a))
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	return nil
 }
 
-func diffBlockStmt(a *ast.BlockStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffBlockStmt(a *ast.BlockStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffBlockStmt:", a, bNode)
 	b, ok := bNode.(*ast.BlockStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, colorMatches(matchStmts(a.List, b.List), mode, "diffBlockStmt")...)
+	coloring = append(coloring, c.colorStmtMatches(a.List, b.List, mode, "diffBlockStmt")...)
 	return
 }
 
-func diffForStmt(a *ast.ForStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffForStmt(a *ast.ForStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffForStmt:", a, bNode)
 	b, ok := bNode.(*ast.ForStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, diff(a.Init, b.Init, mode)...)
-	coloring = append(coloring, diff(a.Cond, b.Cond, mode)...)
-	coloring = append(coloring, diff(a.Post, b.Post, mode)...)
-	coloring = append(coloring, diff(a.Body, b.Body, mode)...)
+	coloring = append(coloring, c.diff(a.Init, b.Init, mode)...)
+	coloring = append(coloring, c.diff(a.Cond, b.Cond, mode)...)
+	coloring = append(coloring, c.diff(a.Post, b.Post, mode)...)
+	coloring = append(coloring, c.diff(a.Body, b.Body, mode)...)
 	return coloring
 }
 
-func diffExprStmt(a *ast.ExprStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffExprStmt(a *ast.ExprStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffExprStmt:", a, bNode)
 	b, ok := bNode.(*ast.ExprStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	return diffExpr(a.X, b.X, mode)
+	return c.diffExpr(a.X, b.X, mode)
 }
 
-func diffIfStmt(a *ast.IfStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffIfStmt(a *ast.IfStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffIfStmt:", a, bNode)
 	b, ok := bNode.(*ast.IfStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, diff(a.Init, b.Init, mode)...)
-	coloring = append(coloring, diff(a.Cond, b.Cond, mode)...)
-	coloring = append(coloring, diff(a.Body, b.Body, mode)...)
+	coloring = append(coloring, c.diff(a.Init, b.Init, mode)...)
+	coloring = append(coloring, c.diff(a.Cond, b.Cond, mode)...)
+	coloring = append(coloring, c.diff(a.Body, b.Body, mode)...)
 	if a.Else != nil {
-		coloring = append(coloring, diff(a.Else, b.Else, mode)...)
+		coloring = append(coloring, c.diff(a.Else, b.Else, mode)...)
 	}
 	return
 }
 
-func diffAssignStmt(a *ast.AssignStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffAssignStmt(a *ast.AssignStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffAssignStmt:", a, bNode)
 	b, ok := bNode.(*ast.AssignStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, colorMatches(matchExprs(a.Lhs, b.Lhs), mode, "diffAssignStmt")...)
-	coloring = append(coloring, colorMatches(matchExprs(a.Rhs, b.Rhs), mode, "diffAssignStmt")...)
+	coloring = append(coloring, c.colorMatches(c.matchExprs(a.Lhs, b.Lhs), mode, "diffAssignStmt")...)
+	coloring = append(coloring, c.colorMatches(c.matchExprs(a.Rhs, b.Rhs), mode, "diffAssignStmt")...)
 
 	return
 }
 
-func diffSwitchStmt(a *ast.SwitchStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffSwitchStmt(a *ast.SwitchStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffSwitchStmt:", a, bNode)
 	b, ok := bNode.(*ast.SwitchStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, diff(a.Init, b.Init, mode)...)
-	coloring = append(coloring, diff(a.Tag, b.Tag, mode)...)
-	coloring = append(coloring, diff(a.Body, b.Body, mode)...)
+	coloring = append(coloring, c.diff(a.Init, b.Init, mode)...)
+	coloring = append(coloring, c.diff(a.Tag, b.Tag, mode)...)
+	coloring = append(coloring, c.diff(a.Body, b.Body, mode)...)
 
 	return
 }
 
-func diffCaseClause(a *ast.CaseClause, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffCaseClause(a *ast.CaseClause, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffCaseClause:", a, bNode)
 	b, ok := bNode.(*ast.CaseClause)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, colorMatches(matchExprs(a.List, b.List), mode, "diffCaseClause")...)
-	coloring = append(coloring, colorMatches(matchStmts(a.Body, b.Body), mode, "diffCaseClause")...)
+	coloring = append(coloring, c.colorMatches(c.matchExprs(a.List, b.List), mode, "diffCaseClause")...)
+	coloring = append(coloring, c.colorStmtMatches(a.Body, b.Body, mode, "diffCaseClause")...)
 	return
 }
 
-func diffDeclStmt(a *ast.DeclStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffDeclStmt(a *ast.DeclStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffDeclStmt:", a, bNode)
 	b, ok := bNode.(*ast.DeclStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	return diffDecl(a.Decl, b.Decl, mode)
+	return c.diffDecl(a.Decl, b.Decl, mode)
 }
 
-func diffTypeSwitchStmt(a *ast.TypeSwitchStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffTypeSwitchStmt(a *ast.TypeSwitchStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffTypeSwitchStmt:", a, bNode)
 	b, ok := bNode.(*ast.TypeSwitchStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
 
-	coloring = append(coloring, diff(a.Assign, b.Assign, mode)...)
-	coloring = append(coloring, diff(a.Init, b.Init, mode)...)
-	coloring = append(coloring, diff(a.Body, b.Body, mode)...)
+	coloring = append(coloring, c.diff(a.Assign, b.Assign, mode)...)
+	coloring = append(coloring, c.diff(a.Init, b.Init, mode)...)
+	coloring = append(coloring, c.diff(a.Body, b.Body, mode)...)
 	return
 }
 
-func diffReturnStmt(a *ast.ReturnStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffReturnStmt(a *ast.ReturnStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffReturnStmt:", a, bNode)
 	b, ok := bNode.(*ast.ReturnStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, colorMatches(matchExprs(a.Results, b.Results), mode, "diffReturnStmt")...)
+	coloring = append(coloring, c.colorMatches(c.matchExprs(a.Results, b.Results), mode, "diffReturnStmt")...)
 	return
 }
 
-func diffRangeStmt(a *ast.RangeStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffRangeStmt(a *ast.RangeStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffRangeStmt:", a, bNode)
 	b, ok := bNode.(*ast.RangeStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
 	if a.Key != nil {
-		coloring = append(coloring, diff(a.Key, b.Key, mode)...)
+		coloring = append(coloring, c.diff(a.Key, b.Key, mode)...)
 	}
 	if a.Value != nil {
-		coloring = append(coloring, diff(a.Value, b.Value, mode)...)
+		coloring = append(coloring, c.diff(a.Value, b.Value, mode)...)
 	}
-	coloring = append(coloring, diff(a.X, b.X, mode)...)
-	coloring = append(coloring, diff(a.Body, b.Body, mode)...)
+	coloring = append(coloring, c.diff(a.X, b.X, mode)...)
+	coloring = append(coloring, c.diff(a.Body, b.Body, mode)...)
 	return
 }
 
-func diffIncDecStmt(a *ast.IncDecStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffIncDecStmt(a *ast.IncDecStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffIncDecStmt:", a, bNode)
 	b, ok := bNode.(*ast.IncDecStmt)
 	if !ok || a.Tok != b.Tok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	return diff(a.X, b.X, mode)
+	return c.diff(a.X, b.X, mode)
 }
 
-func diffBranchStmt(a *ast.BranchStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffLabeledStmt(a *ast.LabeledStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+	logrus.Debugln("diffLabeledStmt:", a, bNode)
+	b, ok := bNode.(*ast.LabeledStmt)
+	if !ok {
+		return Coloring{NewColorChange(mode.ToColor(), a)}
+	}
+	coloring = append(coloring, c.diff(a.Label, b.Label, mode)...)
+	coloring = append(coloring, c.diff(a.Stmt, b.Stmt, mode)...)
+	return
+}
+
+func (c *context) diffBranchStmt(a *ast.BranchStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffBranchStmt:", a, bNode)
 	b, ok := bNode.(*ast.BranchStmt)
 	if !ok || a.Tok != b.Tok {
@@ -205,7 +217,7 @@ func diffBranchStmt(a *ast.BranchStmt, bNode ast.Node, mode Mode) (coloring Colo
 	}
 	if a.Label != nil {
 		if b.Label != nil {
-			return diff(a.Label, b.Label, mode)
+			return c.diff(a.Label, b.Label, mode)
 		} else {
 			return Coloring{NewColorChange(mode.ToColor(), a)}
 		}
@@ -213,7 +225,7 @@ func diffBranchStmt(a *ast.BranchStmt, bNode ast.Node, mode Mode) (coloring Colo
 	return
 }
 
-func diffGoStmt(a *ast.GoStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffGoStmt(a *ast.GoStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffGoStmt:", a, bNode)
 	b, ok := bNode.(*ast.GoStmt)
 	if !ok {
@@ -222,15 +234,15 @@ func diffGoStmt(a *ast.GoStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 		//	return Coloring{NewColorChange(mode.ToColor(), a)}
 		//}
 		//coloring = append(coloring, ColorChange{Color: mode.ToColor(), Pos: a.Go, End: a.Pos()})
-		//coloring = append(coloring, diff(a.Call, b, mode)...)
+		//coloring = append(coloring, c.diff(a.Call, b, mode)...)
 		//return
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = diff(a.Call, b.Call, mode)
+	coloring = c.diff(a.Call, b.Call, mode)
 	return
 }
 
-func diffDeferStmt(a *ast.DeferStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffDeferStmt(a *ast.DeferStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffDeferStmt:", a, bNode)
 	b, ok := bNode.(*ast.DeferStmt)
 	if !ok {
@@ -239,42 +251,42 @@ func diffDeferStmt(a *ast.DeferStmt, bNode ast.Node, mode Mode) (coloring Colori
 		//	return Coloring{NewColorChange(mode.ToColor(), a)}
 		//}
 		//coloring = append(coloring, ColorChange{Color: mode.ToColor(), Pos: a.Defer, End: a.Pos()})
-		//coloring = append(coloring, diff(a.Call, b, mode)...)
+		//coloring = append(coloring, c.diff(a.Call, b, mode)...)
 		//return
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = diff(a.Call, b.Call, mode)
+	coloring = c.diff(a.Call, b.Call, mode)
 	return
 }
 
-func diffSelectStmt(a *ast.SelectStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffSelectStmt(a *ast.SelectStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffSelectStmt:", a, bNode)
 	b, ok := bNode.(*ast.SelectStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = diff(a.Body, b.Body, mode)
+	coloring = c.diff(a.Body, b.Body, mode)
 	return
 }
 
-func diffCommClause(a *ast.CommClause, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffCommClause(a *ast.CommClause, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffCommClause:", a, bNode)
 	b, ok := bNode.(*ast.CommClause)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, diff(a.Comm, b.Comm, mode)...)
-	coloring = append(coloring, colorMatches(matchStmts(a.Body, b.Body), mode, "CommClause")...)
+	coloring = append(coloring, c.diff(a.Comm, b.Comm, mode)...)
+	coloring = append(coloring, c.colorStmtMatches(a.Body, b.Body, mode, "CommClause")...)
 	return
 }
 
-func diffSendStmt(a *ast.SendStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
+func (c *context) diffSendStmt(a *ast.SendStmt, bNode ast.Node, mode Mode) (coloring Coloring) {
 	logrus.Debugln("diffSendStmt:", a, bNode)
 	b, ok := bNode.(*ast.SendStmt)
 	if !ok {
 		return Coloring{NewColorChange(mode.ToColor(), a)}
 	}
-	coloring = append(coloring, diff(a.Chan, b.Chan, mode)...)
-	coloring = append(coloring, diff(a.Value, b.Value, mode)...)
+	coloring = append(coloring, c.diff(a.Chan, b.Chan, mode)...)
+	coloring = append(coloring, c.diff(a.Value, b.Value, mode)...)
 	return
 }
@@ -0,0 +1,40 @@
+package diff
+
+import "fmt"
+
+// Engine is algorithm used to compute colorings of two versions.
+type Engine int
+
+const (
+	// EngineAST is default matcher comparing nodes of the same kind.
+	EngineAST Engine = iota
+	// EngineLCS is text diff based on longest common subsequence.
+	EngineLCS
+	// EngineTree is tree differencing producing edit script of inserts, deletes, updates and moves.
+	EngineTree
+)
+
+var engineNames = []string{"ast", "lcs", "tree"}
+
+// Engines lists all engines.
+var Engines = []Engine{EngineAST, EngineLCS, EngineTree}
+
+func (e Engine) String() string {
+	if int(e) < len(engineNames) {
+		return engineNames[e]
+	}
+	return ""
+}
+
+// ParseEngine returns engine with given name, empty name is EngineAST.
+func ParseEngine(name string) (Engine, error) {
+	if name == "" {
+		return EngineAST, nil
+	}
+	for i, n := range engineNames {
+		if n == name {
+			return Engine(i), nil
+		}
+	}
+	return EngineAST, fmt.Errorf("unknown diff engine: %s", name)
+}
@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"go/ast"
+	"testing"
+)
+
+func TestIsSameGenerics(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"identical", "func F[T any](x T) T { return x }", "func F[T any](x T) T { return x }", true},
+		{"constraint", "func F[T any](x T) T { return x }", "func F[T comparable](x T) T { return x }", false},
+		{"type param added", "func F[T any](x T) {}", "func F[T, U any](x T) {}", false},
+		{"generic and plain", "func F[T any](x T) {}", "func F(x int) {}", false},
+		{"receiver", "func (s *S[T]) F() {}", "func (s *S[E]) F() {}", false},
+		{"receiver list", "func (s *S[K, V]) F() {}", "func (s *S[K, V]) F() {}", true},
+		{"instantiation", "func F() { _ = P[int, string]{} }", "func F() { _ = P[int, bool]{} }", false},
+		{"type", "type S[T any] struct{ x T }", "type S[T any] struct{ x T }", true},
+		{"type constraint", "type S[T any] struct{ x T }", "type S[T ~int | ~string] struct{ x T }", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := IsSame(parseDecl(t, tt.a), parseDecl(t, tt.b)); same != tt.same {
+				t.Errorf("IsSame() = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestSimilarityGenerics(t *testing.T) {
+	a := parseDecl(t, "func Map[T, U any](xs []T, f func(T) U) []U {\n\tvar r []U\n\tfor _, x := range xs {\n\t\tr = append(r, f(x))\n\t}\n\treturn r\n}")
+	b := parseDecl(t, "func Apply[T, U any](xs []T, f func(T) U) []U {\n\tvar r []U\n\tfor _, x := range xs {\n\t\tr = append(r, f(x))\n\t}\n\treturn r\n}")
+	if score := Similarity(a, b); score < 0.99 {
+		t.Errorf("renamed generic function similarity = %v", score)
+	}
+
+	single := parseDecl(t, "func F() { _ = P[int]{} }").(*ast.FuncDecl)
+	list := parseDecl(t, "func F() { _ = P[int, string]{} }").(*ast.FuncDecl)
+	if score := Similarity(single, list); score <= 0 || score >= 1 {
+		t.Errorf("single and multiple type arguments similarity = %v", score)
+	}
+}
+
+func TestDiffGenerics(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		colored  []string
+	}{
+		{"constraint", "func F[T any](x T) T { return x }", "func F[T comparable](x T) T { return x }", []string{"comparable"}},
+		{"type param added", "func F[T any](x T) {}", "func F[T any, U any](x T) {}", []string{"U any"}},
+		{"receiver", "func (s *S[T]) F() {}", "func (s *S[E]) F() {}", []string{"E"}},
+		{"type argument added", "func F() { _ = P[int]{} }", "func F() { _ = P[int, string]{} }", []string{"string"}},
+		{"type argument changed", "func F() { _ = P[int, string]{} }", "func F() { _ = P[int, bool]{} }", []string{"bool"}},
+		{"type constraint", "type S[T any] struct{ x T }", "type S[T ~int] struct{ x T }", []string{"~int"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coloring := Diff(parseDecl(t, tt.new), parseDecl(t, tt.old), ModeNew)
+			got := colored(tt.new, coloring)
+			if len(got) != len(tt.colored) {
+				t.Fatalf("colored %q, want %q", got, tt.colored)
+			}
+			for i := range got {
+				if got[i] != tt.colored[i] {
+					t.Errorf("colored %q, want %q", got, tt.colored)
+				}
+			}
+		})
+	}
+}
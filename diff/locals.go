@@ -0,0 +1,266 @@
+package diff
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// locals resolves identifiers inside node which refer to receivers, parameters, results, type parameters, local
+// declarations and labels to positions of their declarations. Blank identifiers, selected fields, keys of composite
+// literals and field names of struct and interface types are not locals.
+func locals(node ast.Node) vars {
+	r := &resolver{vars: make(vars)}
+	r.open()
+	r.walk(node)
+	return r.vars
+}
+
+type resolver struct {
+	vars   vars
+	scopes []map[string]token.Pos
+	labels map[string]token.Pos
+}
+
+func (r *resolver) open() {
+	r.scopes = append(r.scopes, make(map[string]token.Pos))
+}
+
+func (r *resolver) close() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *resolver) declare(ident *ast.Ident) {
+	if ident.Name == "_" {
+		return
+	}
+	r.scopes[len(r.scopes)-1][ident.Name] = ident.Pos()
+	r.vars[ident] = ident.Pos()
+}
+
+// define declares ident unless it is redeclared in the innermost scope by := statement.
+func (r *resolver) define(expr ast.Expr) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		r.walk(expr)
+		return
+	}
+	if pos, ok := r.scopes[len(r.scopes)-1][ident.Name]; ok {
+		r.vars[ident] = pos
+		return
+	}
+	r.declare(ident)
+}
+
+func (r *resolver) use(ident *ast.Ident) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if pos, ok := r.scopes[i][ident.Name]; ok {
+			r.vars[ident] = pos
+			return
+		}
+	}
+}
+
+func (r *resolver) walk(node ast.Node) {
+	if node == nil {
+		return
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			r.use(n)
+		case *ast.SelectorExpr:
+			r.walk(n.X)
+			return false
+		case *ast.KeyValueExpr:
+			if _, ok := n.Key.(*ast.Ident); !ok {
+				r.walk(n.Key)
+			}
+			r.walk(n.Value)
+			return false
+		case *ast.StructType:
+			r.fieldTypes(n.Fields)
+			return false
+		case *ast.InterfaceType:
+			r.fieldTypes(n.Methods)
+			return false
+		case *ast.FuncType:
+			r.fieldTypes(n.TypeParams)
+			r.fieldTypes(n.Params)
+			r.fieldTypes(n.Results)
+			return false
+		case *ast.FuncDecl:
+			r.function(n.Recv, n.Type, n.Body)
+			return false
+		case *ast.FuncLit:
+			r.function(nil, n.Type, n.Body)
+			return false
+		case *ast.BlockStmt:
+			r.open()
+			r.stmts(n.List)
+			r.close()
+			return false
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				return true
+			}
+			for _, expr := range n.Rhs {
+				r.walk(expr)
+			}
+			for _, expr := range n.Lhs {
+				r.define(expr)
+			}
+			return false
+		case *ast.GenDecl:
+			for _, spec := range n.Specs {
+				r.spec(spec)
+			}
+			return false
+		case *ast.IfStmt:
+			r.open()
+			r.walk(n.Init)
+			r.walk(n.Cond)
+			r.walk(n.Body)
+			r.walk(n.Else)
+			r.close()
+			return false
+		case *ast.ForStmt:
+			r.open()
+			r.walk(n.Init)
+			r.walk(n.Cond)
+			r.walk(n.Post)
+			r.walk(n.Body)
+			r.close()
+			return false
+		case *ast.RangeStmt:
+			r.walk(n.X)
+			r.open()
+			for _, expr := range []ast.Expr{n.Key, n.Value} {
+				if expr == nil {
+					continue
+				}
+				if n.Tok == token.DEFINE {
+					r.define(expr)
+				} else {
+					r.walk(expr)
+				}
+			}
+			r.walk(n.Body)
+			r.close()
+			return false
+		case *ast.SwitchStmt:
+			r.open()
+			r.walk(n.Init)
+			r.walk(n.Tag)
+			r.walk(n.Body)
+			r.close()
+			return false
+		case *ast.TypeSwitchStmt:
+			r.open()
+			r.walk(n.Init)
+			r.walk(n.Assign)
+			r.walk(n.Body)
+			r.close()
+			return false
+		case *ast.CaseClause:
+			r.open()
+			for _, expr := range n.List {
+				r.walk(expr)
+			}
+			r.stmts(n.Body)
+			r.close()
+			return false
+		case *ast.CommClause:
+			r.open()
+			r.walk(n.Comm)
+			r.stmts(n.Body)
+			r.close()
+			return false
+		case *ast.LabeledStmt:
+			r.label(n.Label)
+			r.walk(n.Stmt)
+			return false
+		case *ast.BranchStmt:
+			if n.Label != nil {
+				r.label(n.Label)
+			}
+			return false
+		}
+		return true
+	})
+}
+
+func (r *resolver) stmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		r.walk(stmt)
+	}
+}
+
+func (r *resolver) spec(spec ast.Spec) {
+	switch spec := spec.(type) {
+	case *ast.ValueSpec:
+		r.walk(spec.Type)
+		for _, value := range spec.Values {
+			r.walk(value)
+		}
+		for _, name := range spec.Names {
+			r.declare(name)
+		}
+	case *ast.TypeSpec:
+		r.declare(spec.Name)
+		r.fields(spec.TypeParams)
+		r.walk(spec.Type)
+	}
+}
+
+// function declares receiver, type parameters, parameters, results and labels in new scope of body.
+func (r *resolver) function(recv *ast.FieldList, typ *ast.FuncType, body *ast.BlockStmt) {
+	labels := r.labels
+	r.labels = make(map[string]token.Pos)
+	r.open()
+	r.fields(recv)
+	r.fields(typ.TypeParams)
+	r.fields(typ.Params)
+	r.fields(typ.Results)
+	if body != nil {
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.LabeledStmt:
+				r.labels[n.Label.Name] = n.Label.Pos()
+			}
+			return true
+		})
+		r.stmts(body.List)
+	}
+	r.close()
+	r.labels = labels
+}
+
+func (r *resolver) label(ident *ast.Ident) {
+	if pos, ok := r.labels[ident.Name]; ok {
+		r.vars[ident] = pos
+	}
+}
+
+// fields walks field types and declares field names.
+func (r *resolver) fields(fields *ast.FieldList) {
+	if fields == nil {
+		return
+	}
+	r.fieldTypes(fields)
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			r.declare(name)
+		}
+	}
+}
+
+func (r *resolver) fieldTypes(fields *ast.FieldList) {
+	if fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		r.walk(field.Type)
+	}
+}
@@ -25,12 +25,12 @@ type matching struct {
 	next ast.Node
 }
 
-func matchNodes(a, b []ast.Node, callFunc string) []matching {
+func (c *context) matchNodes(a, b []ast.Node, callFunc string) []matching {
 	matched := make(map[ast.Node]ast.Node)
 	var matchingList matchingElems
 	for _, aStmt := range a {
 		for _, bStmt := range b {
-			score := compare(aStmt, bStmt)
+			score := c.compare(aStmt, bStmt)
 			logrus.Debugln(callFunc+":", reflect.TypeOf(aStmt), aStmt, score, reflect.TypeOf(bStmt), bStmt)
 			if score > 0.0 {
 				matchingList = append(matchingList, matchingElem{aStmt, score, bStmt})
@@ -61,12 +61,12 @@ func matchNodes(a, b []ast.Node, callFunc string) []matching {
 	return result
 }
 
-func matchStmts(a, b []ast.Stmt) []matching {
-	return matchNodes(stmtsToNodes(a), stmtsToNodes(b), "matchStmts")
+func (c *context) matchStmts(a, b []ast.Stmt) []matching {
+	return c.matchNodes(stmtsToNodes(a), stmtsToNodes(b), "matchStmts")
 }
 
-func matchExprs(a, b []ast.Expr) []matching {
-	return matchNodes(exprToNodes(a), exprToNodes(b), "matchExprs")
+func (c *context) matchExprs(a, b []ast.Expr) []matching {
+	return c.matchNodes(exprToNodes(a), exprToNodes(b), "matchExprs")
 }
 
 func stmtsToNodes(l []ast.Stmt) (nodes []ast.Node) {
@@ -83,8 +83,8 @@ func exprToNodes(l []ast.Expr) (nodes []ast.Node) {
 	return
 }
 
-func matchFields(a, b []*ast.Field) []matching {
-	return matchNodes(fieldToNodes(a), fieldToNodes(b), "matchFields")
+func (c *context) matchFields(a, b []*ast.Field) []matching {
+	return c.matchNodes(fieldToNodes(a), fieldToNodes(b), "matchFields")
 }
 
 func fieldToNodes(l []*ast.Field) (nodes []ast.Node) {
@@ -94,8 +94,8 @@ func fieldToNodes(l []*ast.Field) (nodes []ast.Node) {
 	return
 }
 
-func matchIdents(a, b []*ast.Ident) []matching {
-	return matchNodes(identToNodes(a), identToNodes(b), "matchIdents")
+func (c *context) matchIdents(a, b []*ast.Ident) []matching {
+	return c.matchNodes(identToNodes(a), identToNodes(b), "matchIdents")
 }
 
 func identToNodes(l []*ast.Ident) (nodes []ast.Node) {
@@ -105,8 +105,8 @@ func identToNodes(l []*ast.Ident) (nodes []ast.Node) {
 	return
 }
 
-func matchSpecs(a, b []ast.Spec) []matching {
-	return matchNodes(specToNodes(a), specToNodes(b), "matchIdents")
+func (c *context) matchSpecs(a, b []ast.Spec) []matching {
+	return c.matchNodes(specToNodes(a), specToNodes(b), "matchIdents")
 }
 
 func specToNodes(l []ast.Spec) (nodes []ast.Node) {
@@ -0,0 +1,215 @@
+package diff
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// Move links statement moved inside function with its location in the other version, Mode tells which version Pos
+// belongs to.
+type Move struct {
+	Mode       Mode
+	Pos, Other token.Pos
+}
+
+// Key returns position of moved statement in the old version, it is the same on both sides of diff.
+func (m *Move) Key() token.Pos {
+	if m.Mode == ModeOld {
+		return m.Pos
+	}
+	return m.Other
+}
+
+func newMove(mode Mode, a, b ast.Node) *Move {
+	return &Move{Mode: mode, Pos: a.Pos(), Other: b.Pos()}
+}
+
+func (m Mode) opposite() Mode {
+	if m == ModeNew {
+		return ModeOld
+	}
+	return ModeNew
+}
+
+// colorStmtMatches colors statements of a like colorMatches, matched statements which changed their order are
+// additionally colored as moved.
+func (c *context) colorStmtMatches(a, b []ast.Stmt, mode Mode, callFunc string) (coloring Coloring) {
+	matches := c.matchStmts(a, b)
+	moved := reordered(matches, b, mode)
+	for _, match := range matches {
+		if !moved[match.prev] {
+			coloring = append(coloring, c.colorMatches([]matching{match}, mode, callFunc)...)
+			continue
+		}
+		move := newMove(mode, match.prev, match.next)
+		coloring = append(coloring, fill(match.prev, c.diff(match.prev, match.next, mode), ColorMoved, move)...)
+	}
+	return
+}
+
+// reordered returns matched statements which are not part of the heaviest sequence keeping order of both versions,
+// statements are weighted by their size so smaller statements are reported as moved.
+func reordered(matches []matching, b []ast.Stmt, mode Mode) map[ast.Node]bool {
+	index := make(map[ast.Node]int, len(b))
+	for i, stmt := range b {
+		index[stmt] = i
+	}
+	type pair struct {
+		node     ast.Node
+		old, new int
+		weight   int
+	}
+	var pairs []pair
+	for i, match := range matches {
+		if match.next == nil {
+			continue
+		}
+		p := pair{node: match.prev, old: i, new: index[match.next], weight: Size(match.prev)}
+		if mode == ModeNew {
+			p.old, p.new = p.new, p.old
+		}
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].old < pairs[j].old })
+	values, weights := make([]int, len(pairs)), make([]int, len(pairs))
+	for i, p := range pairs {
+		values[i], weights[i] = p.new, p.weight
+	}
+	kept := heaviestIncreasing(values, weights)
+	moved := make(map[ast.Node]bool)
+	for i, p := range pairs {
+		if !kept[i] {
+			moved[p.node] = true
+		}
+	}
+	return moved
+}
+
+// heaviestIncreasing returns indexes of increasing subsequence of values with the biggest sum of weights.
+func heaviestIncreasing(values, weights []int) map[int]bool {
+	best, prev := make([]int, len(values)), make([]int, len(values))
+	last := -1
+	for i := range values {
+		best[i], prev[i] = weights[i], -1
+		for j := 0; j < i; j++ {
+			if values[j] < values[i] && best[j]+weights[i] > best[i] {
+				best[i], prev[i] = best[j]+weights[i], j
+			}
+		}
+		if last == -1 || best[i] > best[last] {
+			last = i
+		}
+	}
+	kept := make(map[int]bool, len(values))
+	for i := last; i != -1; i = prev[i] {
+		kept[i] = true
+	}
+	return kept
+}
+
+// fill colors parts of node which are not colored by ordered inner coloring with given color.
+func fill(node ast.Node, inner Coloring, color Color, move *Move) (coloring Coloring) {
+	pos := node.Pos()
+	for _, change := range inner {
+		if change.Pos > pos {
+			coloring = append(coloring, ColorChange{Color: color, Pos: pos, End: change.Pos - 1, Move: move})
+		}
+		coloring = append(coloring, change)
+		pos = change.End + 1
+	}
+	if end := node.End() - 1; pos <= end {
+		coloring = append(coloring, ColorChange{Color: color, Pos: pos, End: end, Move: move})
+	}
+	return
+}
+
+// paint puts change over ordered coloring, ranges it overlaps are cut.
+func paint(coloring Coloring, change ColorChange) (result Coloring) {
+	added := false
+	for _, c := range coloring {
+		if c.End < change.Pos || c.Pos > change.End {
+			if !added && c.Pos > change.End {
+				result, added = append(result, change), true
+			}
+			result = append(result, c)
+			continue
+		}
+		if c.Pos < change.Pos {
+			before := c
+			before.End = change.Pos - 1
+			result = append(result, before)
+		}
+		if !added {
+			result, added = append(result, change), true
+		}
+		if c.End > change.End {
+			after := c
+			after.Pos = change.End + 1
+			result = append(result, after)
+		}
+	}
+	if !added {
+		result = append(result, change)
+	}
+	return
+}
+
+// changedStmts returns statements of node wholly colored with color, outer statements come first.
+func changedStmts(node ast.Node, coloring Coloring, color Color) (stmts []ast.Stmt) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		stmt, ok := n.(ast.Stmt)
+		if !ok {
+			return true
+		}
+		switch stmt.(type) {
+		case *ast.BlockStmt, *ast.EmptyStmt, *ast.BranchStmt:
+			return true
+		}
+		for _, change := range coloring {
+			if change.Color == color && change.Pos <= stmt.Pos() && change.End >= stmt.End()-1 {
+				stmts = append(stmts, stmt)
+				break
+			}
+		}
+		return true
+	})
+	return
+}
+
+func overlaps(node ast.Node, nodes []ast.Node) bool {
+	for _, n := range nodes {
+		if node.Pos() < n.End() && n.Pos() < node.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// detectMoves recolors statements of a which were removed in one place and added unchanged in another, for example
+// moved into nested block, as moved. coloring is coloring of a compared with b, opposite returns coloring of b compared
+// with a and is called only when a has changed statements.
+func (c *context) detectMoves(a, b ast.Node, coloring Coloring, opposite func() Coloring, mode Mode) Coloring {
+	aStmts := changedStmts(a, coloring, mode.ToColor())
+	if len(aStmts) == 0 {
+		return coloring
+	}
+	bStmts := changedStmts(b, opposite(), mode.opposite().ToColor())
+	var aMoved, bMoved []ast.Node
+	for _, aStmt := range aStmts {
+		if overlaps(aStmt, aMoved) {
+			continue
+		}
+		for _, bStmt := range bStmts {
+			if overlaps(bStmt, bMoved) || !c.same(aStmt, bStmt) {
+				continue
+			}
+			aMoved, bMoved = append(aMoved, aStmt), append(bMoved, bStmt)
+			coloring = paint(coloring, ColorChange{
+				Color: ColorMoved, Pos: aStmt.Pos(), End: aStmt.End() - 1, Move: newMove(mode, aStmt, bStmt),
+			})
+			break
+		}
+	}
+	return coloring
+}
@@ -0,0 +1,51 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffMoves(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		moved    []string
+	}{
+		{"swapped", "func F() int { x := f(1, 2, 3); g(); return x }", "func F() int { g(); x := f(1, 2, 3); return x }", []string{"g()"}},
+		{"to end", "func F() { a(); b(); c(); d() }", "func F() { b(); c(); d(); a() }", []string{"a()"}},
+		{"into block", "func F(c bool) { g(); if c { h() } }", "func F(c bool) { if c { h(); g() } }", []string{"g()"}},
+		{"out of removed block", "func F(c bool) { if c { x := f(1, 2); use(x) } }", "func F(c bool) { x := f(1, 2); use(x) }", []string{"x := f(1, 2)", "use(x)"}},
+		{"added", "func F() { a() }", "func F() { a(); b() }", nil},
+		{"changed", "func F() { a(); b() }", "func F() { a(); c() }", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parseDecl(t, tt.old), parseDecl(t, tt.new)
+			if left, right := DiffPair(a, b); !reflect.DeepEqual(left, Diff(a, b, ModeOld)) ||
+				!reflect.DeepEqual(right, Diff(b, a, ModeNew)) {
+				t.Errorf("DiffPair() = %v, %v, want the same as Diff() of both sides", left, right)
+			}
+			for _, side := range []struct {
+				src  string
+				mode Mode
+				diff Coloring
+			}{
+				{tt.old, ModeOld, Diff(a, b, ModeOld)},
+				{tt.new, ModeNew, Diff(b, a, ModeNew)},
+			} {
+				var moved Coloring
+				for _, change := range side.diff {
+					if change.Color == ColorMoved {
+						moved = append(moved, change)
+						if change.Move == nil || change.Move.Mode != side.mode {
+							t.Errorf("%v: move of %v = %v", side.mode, change, change.Move)
+						}
+					}
+				}
+				if got := colored(side.src, moved); !reflect.DeepEqual(got, tt.moved) {
+					t.Errorf("%v: moved = %q, want %q", side.mode, got, tt.moved)
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,11 @@ var (
 )
 
 func IsSame(aNode, bNode ast.Node) bool {
+	return plain.same(aNode, bNode)
+}
+
+// same compares nodes like isSame, locals matched during failed comparison are unbound again.
+func (c *context) same(aNode, bNode ast.Node) bool {
 	if aNode != nil {
 		depth := int64(getDepth(aNode, 0))
 		if depth > 1 {
@@ -26,10 +31,15 @@ func IsSame(aNode, bNode ast.Node) bool {
 	//	atomic.AddInt64(&CountSameCalls, 1)
 	//	atomic.AddInt64(&Depth, int64(getDepth(bNode, 0)))
 	//}
-	return isSame(aNode, bNode)
+	mark := c.mark()
+	if !c.isSame(aNode, bNode) {
+		c.unbind(mark)
+		return false
+	}
+	return true
 }
 
-func isSame(aNode, bNode ast.Node) bool {
+func (c *context) isSame(aNode, bNode ast.Node) bool {
 	if aNode == nil {
 		return bNode == nil
 	} else {
@@ -50,7 +60,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Elt, b.Elt) && IsSame(a.Len, b.Len)
+		return c.same(a.Elt, b.Elt) && c.same(a.Len, b.Len)
 	case *ast.AssignStmt:
 		b, ok := bNode.(*ast.AssignStmt)
 		if !ok {
@@ -67,12 +77,12 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.Lhs); i++ {
-			if !IsSame(a.Lhs[i], b.Lhs[i]) {
+			if !c.same(a.Lhs[i], b.Lhs[i]) {
 				return false
 			}
 		}
 		for i := 0; i < len(a.Rhs); i++ {
-			if !IsSame(a.Rhs[i], b.Rhs[i]) {
+			if !c.same(a.Rhs[i], b.Rhs[i]) {
 				return false
 			}
 		}
@@ -141,7 +151,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return a.Op == b.Op && IsSame(a.X, b.X) && IsSame(a.Y, b.Y)
+		return a.Op == b.Op && c.same(a.X, b.X) && c.same(a.Y, b.Y)
 	case *ast.BlockStmt:
 		b, ok := bNode.(*ast.BlockStmt)
 		if !ok {
@@ -158,7 +168,7 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.List); i++ {
-			if !IsSame(a.List[i], b.List[i]) {
+			if !c.same(a.List[i], b.List[i]) {
 				return false
 			}
 		}
@@ -175,7 +185,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return a.Tok == b.Tok && IsSame(a.Label, b.Label)
+		return a.Tok == b.Tok && c.same(a.Label, b.Label)
 	case *ast.CallExpr:
 		b, ok := bNode.(*ast.CallExpr)
 		if !ok {
@@ -192,11 +202,11 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.Args); i++ {
-			if !IsSame(a.Args[i], b.Args[i]) {
+			if !c.same(a.Args[i], b.Args[i]) {
 				return false
 			}
 		}
-		return IsSame(a.Fun, b.Fun)
+		return c.same(a.Fun, b.Fun)
 	case *ast.CaseClause:
 		b, ok := bNode.(*ast.CaseClause)
 		if !ok {
@@ -213,12 +223,12 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.List); i++ {
-			if !IsSame(a.List[i], b.List[i]) {
+			if !c.same(a.List[i], b.List[i]) {
 				return false
 			}
 		}
 		for i := 0; i < len(a.Body); i++ {
-			if !IsSame(a.Body[i], b.Body[i]) {
+			if !c.same(a.Body[i], b.Body[i]) {
 				return false
 			}
 		}
@@ -235,7 +245,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Value, b.Value) && a.Dir == b.Dir
+		return c.same(a.Value, b.Value) && a.Dir == b.Dir
 	case *ast.CommClause:
 		b, ok := bNode.(*ast.CommClause)
 		if !ok {
@@ -252,11 +262,11 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.Body); i++ {
-			if !IsSame(a.Body[i], b.Body[i]) {
+			if !c.same(a.Body[i], b.Body[i]) {
 				return false
 			}
 		}
-		return IsSame(a.Comm, b.Comm)
+		return c.same(a.Comm, b.Comm)
 	case *ast.Comment:
 		_, ok := bNode.(*ast.Comment)
 		if !ok {
@@ -285,11 +295,11 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.Elts); i++ {
-			if !IsSame(a.Elts[i], b.Elts[i]) {
+			if !c.same(a.Elts[i], b.Elts[i]) {
 				return false
 			}
 		}
-		return IsSame(a.Type, b.Type)
+		return c.same(a.Type, b.Type)
 	case *ast.DeclStmt:
 		b, ok := bNode.(*ast.DeclStmt)
 		if !ok {
@@ -302,7 +312,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Decl, b.Decl)
+		return c.same(a.Decl, b.Decl)
 	case *ast.DeferStmt:
 		b, ok := bNode.(*ast.DeferStmt)
 		if !ok {
@@ -315,7 +325,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Call, b.Call)
+		return c.same(a.Call, b.Call)
 	case *ast.Ellipsis:
 		b, ok := bNode.(*ast.Ellipsis)
 		if !ok {
@@ -328,7 +338,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Elt, b.Elt)
+		return c.same(a.Elt, b.Elt)
 	case *ast.EmptyStmt:
 		b, ok := bNode.(*ast.EmptyStmt)
 		if !ok {
@@ -354,7 +364,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.X, b.X)
+		return c.same(a.X, b.X)
 	case *ast.Field:
 		b, ok := bNode.(*ast.Field)
 		if !ok {
@@ -371,11 +381,11 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.Names); i++ {
-			if !IsSame(a.Names[i], b.Names[i]) {
+			if !c.same(a.Names[i], b.Names[i]) {
 				return false
 			}
 		}
-		return IsSame(a.Type, b.Type)
+		return c.same(a.Type, b.Type) && c.same(a.Tag, b.Tag)
 	case *ast.FieldList:
 		b, ok := bNode.(*ast.FieldList)
 		if !ok {
@@ -392,7 +402,7 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.List); i++ {
-			if !IsSame(a.List[i], b.List[i]) {
+			if !c.same(a.List[i], b.List[i]) {
 				return false
 			}
 		}
@@ -409,7 +419,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Init, b.Init) && IsSame(a.Cond, b.Cond) && IsSame(a.Post, b.Post) && IsSame(a.Body, b.Body)
+		return c.same(a.Init, b.Init) && c.same(a.Cond, b.Cond) && c.same(a.Post, b.Post) && c.same(a.Body, b.Body)
 	case *ast.FuncDecl:
 		b, ok := bNode.(*ast.FuncDecl)
 		if !ok {
@@ -422,7 +432,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Name, b.Name) && IsSame(a.Type, b.Type) && IsSame(a.Body, b.Body) // skip comments compare
+		return c.same(a.Recv, b.Recv) && c.same(a.Name, b.Name) && c.same(a.Type, b.Type) && c.same(a.Body, b.Body) // skip comments compare
 	case *ast.FuncLit:
 		b, ok := bNode.(*ast.FuncLit)
 		if !ok {
@@ -435,7 +445,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Type, b.Type) && IsSame(a.Body, b.Body)
+		return c.same(a.Type, b.Type) && c.same(a.Body, b.Body)
 	case *ast.FuncType:
 		b, ok := bNode.(*ast.FuncType)
 		if !ok {
@@ -448,7 +458,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Params, b.Params) && IsSame(a.Results, b.Results)
+		return c.same(a.TypeParams, b.TypeParams) && c.same(a.Params, b.Params) && c.same(a.Results, b.Results)
 	case *ast.GenDecl:
 		b, ok := bNode.(*ast.GenDecl)
 		if !ok {
@@ -461,11 +471,11 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		if len(a.Specs) != len(b.Specs) {
+		if a.Tok != b.Tok || len(a.Specs) != len(b.Specs) {
 			return false
 		}
 		for i := 0; i < len(a.Specs); i++ {
-			if !IsSame(a.Specs[i], b.Specs[i]) {
+			if !c.same(a.Specs[i], b.Specs[i]) {
 				return false
 			}
 		}
@@ -482,7 +492,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Call, b.Call)
+		return c.same(a.Call, b.Call)
 	case *ast.Ident:
 		b, ok := bNode.(*ast.Ident)
 		if !ok {
@@ -495,7 +505,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return a.Name == b.Name // TODO: maybe improve that one
+		return c.sameIdent(a, b)
 	case *ast.IfStmt:
 		b, ok := bNode.(*ast.IfStmt)
 		if !ok {
@@ -508,7 +518,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Init, b.Init) && IsSame(a.Cond, b.Cond) && IsSame(a.Body, b.Body) && IsSame(a.Else, b.Else)
+		return c.same(a.Init, b.Init) && c.same(a.Cond, b.Cond) && c.same(a.Body, b.Body) && c.same(a.Else, b.Else)
 	case *ast.ImportSpec:
 		b, ok := bNode.(*ast.ImportSpec)
 		if !ok {
@@ -521,7 +531,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Name, b.Name)
+		return c.same(a.Name, b.Name)
 	case *ast.IncDecStmt:
 		b, ok := bNode.(*ast.IncDecStmt)
 		if !ok {
@@ -534,7 +544,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return a.Tok == b.Tok && IsSame(a.X, b.X)
+		return a.Tok == b.Tok && c.same(a.X, b.X)
 	case *ast.IndexExpr:
 		b, ok := bNode.(*ast.IndexExpr)
 		if !ok {
@@ -547,7 +557,28 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.X, b.X) && IsSame(a.Index, b.Index)
+		return c.same(a.X, b.X) && c.same(a.Index, b.Index)
+	case *ast.IndexListExpr:
+		b, ok := bNode.(*ast.IndexListExpr)
+		if !ok {
+			return false
+		}
+		if a == nil {
+			return b == nil
+		} else {
+			if b == nil {
+				return false
+			}
+		}
+		if len(a.Indices) != len(b.Indices) {
+			return false
+		}
+		for i := 0; i < len(a.Indices); i++ {
+			if !c.same(a.Indices[i], b.Indices[i]) {
+				return false
+			}
+		}
+		return c.same(a.X, b.X)
 	case *ast.InterfaceType:
 		b, ok := bNode.(*ast.InterfaceType)
 		if !ok {
@@ -560,7 +591,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Methods, b.Methods)
+		return c.same(a.Methods, b.Methods)
 	case *ast.KeyValueExpr:
 		b, ok := bNode.(*ast.KeyValueExpr)
 		if !ok {
@@ -573,7 +604,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Value, b.Value) && IsSame(a.Key, b.Key)
+		return c.same(a.Value, b.Value) && c.same(a.Key, b.Key)
 	case *ast.LabeledStmt:
 		b, ok := bNode.(*ast.LabeledStmt)
 		if !ok {
@@ -586,7 +617,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Label, b.Label) && IsSame(a.Stmt, b.Stmt)
+		return c.same(a.Label, b.Label) && c.same(a.Stmt, b.Stmt)
 	case *ast.MapType:
 		b, ok := bNode.(*ast.MapType)
 		if !ok {
@@ -599,7 +630,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Key, b.Key) && IsSame(a.Value, b.Value)
+		return c.same(a.Key, b.Key) && c.same(a.Value, b.Value)
 	case *ast.Package:
 		b, ok := bNode.(*ast.Package)
 		if !ok {
@@ -625,7 +656,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.X, b.X)
+		return c.same(a.X, b.X)
 	case *ast.RangeStmt:
 		b, ok := bNode.(*ast.RangeStmt)
 		if !ok {
@@ -638,7 +669,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Key, b.Key) && IsSame(a.Value, b.Value) && IsSame(a.X, b.X) && IsSame(a.Body, b.Body)
+		return c.same(a.Key, b.Key) && c.same(a.Value, b.Value) && c.same(a.X, b.X) && c.same(a.Body, b.Body)
 	case *ast.ReturnStmt:
 		b, ok := bNode.(*ast.ReturnStmt)
 		if !ok {
@@ -655,7 +686,7 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.Results); i++ {
-			if !IsSame(a.Results[i], b.Results[i]) {
+			if !c.same(a.Results[i], b.Results[i]) {
 				return false
 			}
 		}
@@ -672,7 +703,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Body, b.Body)
+		return c.same(a.Body, b.Body)
 	case *ast.SelectorExpr:
 		b, ok := bNode.(*ast.SelectorExpr)
 		if !ok {
@@ -685,7 +716,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.X, b.X) && IsSame(a.Sel, b.Sel)
+		return c.same(a.X, b.X) && c.same(a.Sel, b.Sel)
 	case *ast.SendStmt:
 		b, ok := bNode.(*ast.SendStmt)
 		if !ok {
@@ -698,7 +729,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Value, b.Value) && IsSame(a.Chan, b.Chan)
+		return c.same(a.Value, b.Value) && c.same(a.Chan, b.Chan)
 	case *ast.SliceExpr:
 		b, ok := bNode.(*ast.SliceExpr)
 		if !ok {
@@ -711,7 +742,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.X, b.X) && IsSame(a.High, b.High) && IsSame(a.Low, b.Low) && IsSame(a.Max, b.Max)
+		return c.same(a.X, b.X) && c.same(a.High, b.High) && c.same(a.Low, b.Low) && c.same(a.Max, b.Max)
 	case *ast.StarExpr:
 		b, ok := bNode.(*ast.StarExpr)
 		if !ok {
@@ -724,7 +755,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.X, b.X)
+		return c.same(a.X, b.X)
 	case *ast.StructType:
 		b, ok := bNode.(*ast.StructType)
 		if !ok {
@@ -737,7 +768,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Fields, b.Fields)
+		return c.same(a.Fields, b.Fields)
 	case *ast.SwitchStmt:
 		b, ok := bNode.(*ast.SwitchStmt)
 		if !ok {
@@ -750,7 +781,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Init, b.Init) && IsSame(a.Tag, b.Tag) && IsSame(a.Body, b.Body)
+		return c.same(a.Init, b.Init) && c.same(a.Tag, b.Tag) && c.same(a.Body, b.Body)
 	case *ast.TypeAssertExpr:
 		b, ok := bNode.(*ast.TypeAssertExpr)
 		if !ok {
@@ -763,7 +794,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.X, b.X) && IsSame(a.Type, b.Type)
+		return c.same(a.X, b.X) && c.same(a.Type, b.Type)
 	case *ast.TypeSpec:
 		b, ok := bNode.(*ast.TypeSpec)
 		if !ok {
@@ -776,7 +807,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Type, b.Type) && IsSame(a.Name, b.Name)
+		return a.Assign.IsValid() == b.Assign.IsValid() && c.same(a.TypeParams, b.TypeParams) && c.same(a.Type, b.Type) && c.same(a.Name, b.Name)
 	case *ast.TypeSwitchStmt:
 		b, ok := bNode.(*ast.TypeSwitchStmt)
 		if !ok {
@@ -789,7 +820,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return IsSame(a.Assign, b.Assign) && IsSame(a.Init, b.Init) && IsSame(a.Body, b.Body)
+		return c.same(a.Assign, b.Assign) && c.same(a.Init, b.Init) && c.same(a.Body, b.Body)
 	case *ast.UnaryExpr:
 		b, ok := bNode.(*ast.UnaryExpr)
 		if !ok {
@@ -802,7 +833,7 @@ func isSame(aNode, bNode ast.Node) bool {
 				return false
 			}
 		}
-		return a.Op == b.Op && IsSame(a.X, b.X)
+		return a.Op == b.Op && c.same(a.X, b.X)
 	case *ast.ValueSpec:
 		b, ok := bNode.(*ast.ValueSpec)
 		if !ok {
@@ -819,16 +850,16 @@ func isSame(aNode, bNode ast.Node) bool {
 			return false
 		}
 		for i := 0; i < len(a.Names); i++ {
-			if !IsSame(a.Names[i], b.Names[i]) {
+			if !c.same(a.Names[i], b.Names[i]) {
 				return false
 			}
 		}
 		for i := 0; i < len(a.Values); i++ {
-			if !IsSame(a.Values[i], b.Values[i]) {
+			if !c.same(a.Values[i], b.Values[i]) {
 				return false
 			}
 		}
-		return IsSame(a.Type, b.Type)
+		return c.same(a.Type, b.Type)
 	default:
 		logrus.Errorln("unimplemented case:", reflect.TypeOf(a), reflect.TypeOf(bNode))
 		return false
@@ -1039,7 +1070,7 @@ func getDepth(aNode ast.Node, depth int) int {
 		if a == nil {
 			return depth
 		}
-		return max(getDepth(a.Params, depth), getDepth(a.Results, depth))
+		return max(getDepth(a.TypeParams, depth), max(getDepth(a.Params, depth), getDepth(a.Results, depth)))
 	case *ast.GenDecl:
 		if a == nil {
 			return depth
@@ -1080,6 +1111,15 @@ func getDepth(aNode ast.Node, depth int) int {
 			return depth
 		}
 		return max(getDepth(a.X, depth), getDepth(a.Index, depth))
+	case *ast.IndexListExpr:
+		if a == nil {
+			return depth
+		}
+		m := depth
+		for i := 0; i < len(a.Indices); i++ {
+			m = max(m, getDepth(a.Indices[i], depth))
+		}
+		return max(m, getDepth(a.X, depth))
 	case *ast.InterfaceType:
 		if a == nil {
 			return depth
@@ -1169,7 +1209,7 @@ func getDepth(aNode ast.Node, depth int) int {
 		if a == nil {
 			return depth
 		}
-		return max(getDepth(a.Type, depth), getDepth(a.Name, depth))
+		return max(getDepth(a.TypeParams, depth), max(getDepth(a.Type, depth), getDepth(a.Name, depth)))
 	case *ast.TypeSwitchStmt:
 		if a == nil {
 			return depth
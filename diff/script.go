@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"go/ast"
+)
+
+// ActionKind is kind of edit script action.
+type ActionKind int
+
+const (
+	// ActionInsert adds node present only in the new version.
+	ActionInsert ActionKind = iota
+	// ActionDelete removes node present only in the old version.
+	ActionDelete
+	// ActionUpdate changes identifier, literal or operator of matched node.
+	ActionUpdate
+	// ActionMove puts matched node under different parent or changes its order among siblings.
+	ActionMove
+)
+
+var actionNames = []string{"insert", "delete", "update", "move"}
+
+func (k ActionKind) String() string {
+	if int(k) < len(actionNames) {
+		return actionNames[k]
+	}
+	return ""
+}
+
+// Match is pair of nodes of the old and new version matched by tree differencing.
+type Match struct {
+	Old, New ast.Node
+}
+
+// Action is single operation of edit script, Old is nil for inserts and New is nil for deletes.
+type Action struct {
+	Kind     ActionKind
+	Old, New ast.Node
+}
+
+// Script is result of tree differencing of two versions, Matches lists all matched nodes in preorder of the old
+// version and Actions is edit script transforming the old version into the new one. Deletes, updates and moves come
+// in preorder of the old version, followed by inserts in preorder of the new one. Positions of old and new nodes
+// refer to files of their versions.
+type Script struct {
+	Matches []Match
+	Actions []Action
+
+	matcher  *matcher
+	actions  []action
+	old, new map[ast.Node]ast.Node
+}
+
+// EditScript matches nodes of old and new in GumTree fashion and returns edit script between them, comments are not
+// part of the script.
+func EditScript(old, new ast.Node) *Script {
+	m := newMatcher(old, new)
+	s := &Script{matcher: m, actions: m.script(), old: make(map[ast.Node]ast.Node), new: make(map[ast.Node]ast.Node)}
+	for _, t := range m.old {
+		if partner, ok := m.src[t]; ok {
+			s.Matches = append(s.Matches, Match{Old: t.node, New: partner.node})
+			s.new[t.node], s.old[partner.node] = partner.node, t.node
+		}
+	}
+	for _, a := range s.actions {
+		action := Action{Kind: a.kind}
+		if a.old != nil {
+			action.Old = a.old.node
+		}
+		if a.new != nil {
+			action.New = a.new.node
+		}
+		s.Actions = append(s.Actions, action)
+	}
+	return s
+}
+
+// NewNode returns node of the new version matched with node of the old version, it is nil for deleted nodes.
+func (s *Script) NewNode(old ast.Node) ast.Node {
+	return s.new[old]
+}
+
+// OldNode returns node of the old version matched with node of the new version, it is nil for inserted nodes.
+func (s *Script) OldNode(new ast.Node) ast.Node {
+	return s.old[new]
+}
+
+// Coloring returns coloring of the old version for ModeOld and of the new one for ModeNew.
+func (s *Script) Coloring(mode Mode) Coloring {
+	return s.matcher.coloring(s.actions, mode)
+}
@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"go/ast"
+	"reflect"
+	"testing"
+)
+
+func TestEditScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		actions  []string
+	}{
+		{"same", "func F(x int) int { return x + 1 }", "func F(x int) int { return x + 1 }", nil},
+		{"rename", "func F() int { x := 1; return x }", "func F() int { y := 1; return y }", []string{"update x -> y", "update x -> y"}},
+		{"operator", "func F(a, b int) int { return a + b }", "func F(a, b int) int { return a - b }", []string{"update a + b -> a - b"}},
+		{"insert", "func F() { a(1) }", "func F() { a(1); b(2) }", []string{"insert b(2)", "insert b(2)", "insert b", "insert 2"}},
+		{"delete", "func F() { a(1); b(2) }", "func F() { a(1) }", []string{"delete b(2)", "delete b(2)", "delete b", "delete 2"}},
+		{"move", "func F() int { x := f(1, 2, 3); g(4); return x }", "func F() int { g(4); x := f(1, 2, 3); return x }", []string{"move g(4) -> g(4)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parseDecl(t, tt.old), parseDecl(t, tt.new)
+			text := func(src string, node ast.Node) string {
+				return ("package p\n" + src)[node.Pos()-1 : node.End()-1]
+			}
+			script := EditScript(a, b)
+			var actions []string
+			for _, action := range script.Actions {
+				s := action.Kind.String()
+				if action.Old != nil {
+					s += " " + text(tt.old, action.Old)
+				}
+				if action.Old != nil && action.New != nil {
+					s += " ->"
+				}
+				if action.New != nil {
+					s += " " + text(tt.new, action.New)
+				}
+				actions = append(actions, s)
+			}
+			if !reflect.DeepEqual(actions, tt.actions) {
+				t.Errorf("Actions = %q, want %q", actions, tt.actions)
+			}
+			if script.NewNode(a) != b || script.OldNode(b) != a {
+				t.Errorf("declarations are not matched")
+			}
+			for _, match := range script.Matches {
+				if script.NewNode(match.Old) != match.New || script.OldNode(match.New) != match.Old {
+					t.Errorf("match %s -> %s is not in lookups", text(tt.old, match.Old), text(tt.new, match.New))
+				}
+			}
+			for _, action := range script.Actions {
+				if action.Kind == ActionInsert && script.OldNode(action.New) != nil {
+					t.Errorf("inserted %s is matched", text(tt.new, action.New))
+				}
+			}
+		})
+	}
+
+	script := EditScript(nil, parseDecl(t, "func F() {}"))
+	if len(script.Matches) != 0 || len(script.Actions) == 0 || script.Actions[0].Kind != ActionInsert {
+		t.Errorf("EditScript(nil) = %v, want only inserts", script.Actions)
+	}
+}
@@ -0,0 +1,27 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		size int
+	}{
+		{"empty", "func F() {}", 5},
+		{"return", "func F() int { return 1 }", 10},
+		{"switch", "func F(x int) { switch x { case 1: } }", 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if size := Size(parseDecl(t, tt.src)); size != tt.size {
+				t.Errorf("Size() = %d, want %d", size, tt.size)
+			}
+		})
+	}
+	if size := Size(nil); size != 0 {
+		t.Errorf("Size(nil) = %d, want 0", size)
+	}
+}
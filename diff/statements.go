@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"go/ast"
+)
+
+// MatchStatements pairs statements of a with statements of b, nested statements are paired only when their parents
+// are paired. a and b should be both declarations or both statements, unpaired statements are missing in result.
+func MatchStatements(a, b ast.Node) map[ast.Stmt]ast.Stmt {
+	result := make(map[ast.Stmt]ast.Stmt)
+	matchStatements(a, b, result)
+	return result
+}
+
+func matchStatements(a, b ast.Node, result map[ast.Stmt]ast.Stmt) {
+	aGroups, bGroups := statementGroups(a), statementGroups(b)
+	for i := 0; i < len(aGroups) && i < len(bGroups); i++ {
+		for _, match := range plain.matchStmts(aGroups[i], bGroups[i]) {
+			if match.next == nil {
+				continue
+			}
+			prev, next := match.prev.(ast.Stmt), match.next.(ast.Stmt)
+			result[prev] = next
+			matchStatements(prev, next, result)
+		}
+	}
+}
+
+// statementGroups returns lists of statements directly nested in node, every branch is a separate group.
+func statementGroups(node ast.Node) [][]ast.Stmt {
+	block := func(b *ast.BlockStmt) []ast.Stmt {
+		if b == nil {
+			return nil
+		}
+		return b.List
+	}
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		return [][]ast.Stmt{block(n.Body)}
+	case *ast.BlockStmt:
+		return [][]ast.Stmt{n.List}
+	case *ast.IfStmt:
+		switch e := n.Else.(type) {
+		case *ast.BlockStmt:
+			return [][]ast.Stmt{block(n.Body), e.List}
+		case nil:
+			return [][]ast.Stmt{block(n.Body)}
+		default:
+			return [][]ast.Stmt{block(n.Body), {e}}
+		}
+	case *ast.ForStmt:
+		return [][]ast.Stmt{block(n.Body)}
+	case *ast.RangeStmt:
+		return [][]ast.Stmt{block(n.Body)}
+	case *ast.SwitchStmt:
+		return [][]ast.Stmt{block(n.Body)}
+	case *ast.TypeSwitchStmt:
+		return [][]ast.Stmt{block(n.Body)}
+	case *ast.SelectStmt:
+		return [][]ast.Stmt{block(n.Body)}
+	case *ast.CaseClause:
+		return [][]ast.Stmt{n.Body}
+	case *ast.CommClause:
+		return [][]ast.Stmt{n.Body}
+	case *ast.LabeledStmt:
+		return [][]ast.Stmt{{n.Stmt}}
+	default:
+		return nil
+	}
+}
+
+// IsSameHeader compares nodes without statements nested in them, so compound statement with changed body
+// is still considered the same.
+func IsSameHeader(aNode, bNode ast.Node) bool {
+	switch a := aNode.(type) {
+	case *ast.FuncDecl:
+		b, ok := bNode.(*ast.FuncDecl)
+		return ok && IsSame(a.Recv, b.Recv) && IsSame(a.Name, b.Name) && IsSame(a.Type, b.Type)
+	case *ast.BlockStmt:
+		_, ok := bNode.(*ast.BlockStmt)
+		return ok
+	case *ast.IfStmt:
+		b, ok := bNode.(*ast.IfStmt)
+		return ok && IsSame(a.Init, b.Init) && IsSame(a.Cond, b.Cond)
+	case *ast.ForStmt:
+		b, ok := bNode.(*ast.ForStmt)
+		return ok && IsSame(a.Init, b.Init) && IsSame(a.Cond, b.Cond) && IsSame(a.Post, b.Post)
+	case *ast.RangeStmt:
+		b, ok := bNode.(*ast.RangeStmt)
+		return ok && a.Tok == b.Tok && IsSame(a.Key, b.Key) && IsSame(a.Value, b.Value) && IsSame(a.X, b.X)
+	case *ast.SwitchStmt:
+		b, ok := bNode.(*ast.SwitchStmt)
+		return ok && IsSame(a.Init, b.Init) && IsSame(a.Tag, b.Tag)
+	case *ast.TypeSwitchStmt:
+		b, ok := bNode.(*ast.TypeSwitchStmt)
+		return ok && IsSame(a.Init, b.Init) && IsSame(a.Assign, b.Assign)
+	case *ast.SelectStmt:
+		_, ok := bNode.(*ast.SelectStmt)
+		return ok
+	case *ast.CaseClause:
+		b, ok := bNode.(*ast.CaseClause)
+		if !ok || len(a.List) != len(b.List) {
+			return false
+		}
+		for i := range a.List {
+			if !IsSame(a.List[i], b.List[i]) {
+				return false
+			}
+		}
+		return true
+	case *ast.CommClause:
+		b, ok := bNode.(*ast.CommClause)
+		return ok && IsSame(a.Comm, b.Comm)
+	case *ast.LabeledStmt:
+		b, ok := bNode.(*ast.LabeledStmt)
+		return ok && IsSame(a.Label, b.Label)
+	default:
+		return IsSame(aNode, bNode)
+	}
+}
@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"go/ast"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// text returns fragment of src covered by node, src has to be parsed with parseDecl.
+func text(src string, node ast.Node) string {
+	return ("package p\n" + src)[node.Pos()-1 : node.End()-1]
+}
+
+func TestMatchStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		pairs []string
+	}{
+		{"same", "func F() { a(); b() }", "func F() { a(); b() }", []string{"a() = a()", "b() = b()"}},
+		{"inserted", "func F() { a(); b() }", "func F() { a(); x(); b() }", []string{"a() = a()", "b() = b()"}},
+		{"removed", "func F() { a(); x(); b() }", "func F() { a(); b() }", []string{"a() = a()", "b() = b()"}},
+		{"nested", "func F() { if x { a() } }", "func F() { if x { a(); b() } }",
+			[]string{"a() = a()", "if x { a() } = if x { a(); b() }"}},
+		{"branches", "func F() { if x { a() } else { b() } }", "func F() { if x { b() } else { a() } }",
+			[]string{"if x { a() } else { b() } = if x { b() } else { a() }"}},
+		{"case", "func F() { switch x { case 1: a() } }", "func F() { switch x { case 1: a(); b() } }",
+			[]string{"a() = a()", "case 1: a() = case 1: a(); b()", "switch x { case 1: a() } = switch x { case 1: a(); b() }"}},
+		{"header only", "func F() {}", "func F(x int) {}", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pairs []string
+			for a, b := range MatchStatements(parseDecl(t, tt.a), parseDecl(t, tt.b)) {
+				pairs = append(pairs, text(tt.a, a)+" = "+text(tt.b, b))
+			}
+			sort.Strings(pairs)
+			if !reflect.DeepEqual(pairs, tt.pairs) {
+				t.Errorf("MatchStatements() = %q, want %q", pairs, tt.pairs)
+			}
+		})
+	}
+}
+
+func TestIsSameHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"func body", "func F(x int) { a() }", "func F(x int) { b() }", true},
+		{"func params", "func F(x int) { a() }", "func F(x string) { a() }", false},
+		{"func receiver", "func (s S) F() {}", "func (s *S) F() {}", false},
+		{"if body", "if x { a() }", "if x { b() }", true},
+		{"if condition", "if x { a() }", "if y { a() }", false},
+		{"for body", "for i := 0; i < n; i++ { a() }", "for i := 0; i < n; i++ { b() }", true},
+		{"for post", "for i := 0; i < n; i++ {}", "for i := 0; i < n; i-- {}", false},
+		{"range body", "for k := range m { a() }", "for k := range m { b() }", true},
+		{"range key", "for k := range m {}", "for _, v := range m {}", false},
+		{"switch body", "switch x { case 1: a() }", "switch x { case 1: b() }", true},
+		{"switch tag", "switch x { case 1: a() }", "switch y { case 1: a() }", false},
+		{"labeled", "L: for { a() }", "L: for { b() }", true},
+		{"simple", "a()", "b()", false},
+		{"kind", "if x {}", "for x {}", false},
+	}
+	// node parses declaration or statement wrapped in function
+	node := func(t *testing.T, src string) ast.Node {
+		if strings.HasPrefix(src, "func ") {
+			return parseDecl(t, src)
+		}
+		return parseDecl(t, "func F() { "+src+" }").(*ast.FuncDecl).Body.List[0]
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := IsSameHeader(node(t, tt.a), node(t, tt.b)); same != tt.same {
+				t.Errorf("IsSameHeader() = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
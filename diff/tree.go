@@ -0,0 +1,531 @@
+package diff
+
+import (
+	"go/ast"
+	"go/token"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+const (
+	// minHeight is minimal height of isomorphic subtrees matched in top-down phase.
+	minHeight = 2
+	// minDice is minimal ratio of common descendants of containers matched in bottom-up phase.
+	minDice = 0.5
+)
+
+// tree is AST node prepared for tree differencing, label is type of node and value its identifier, literal or
+// operator.
+type tree struct {
+	node     ast.Node
+	label    string
+	value    string
+	parent   *tree
+	children []*tree
+	// index is position of tree in preorder and last is index of its last descendant.
+	index, last  int
+	height, size int
+	hash         uint64
+}
+
+// newTree returns nodes of tree built from root in preorder, comments are left out.
+func newTree(root ast.Node) (nodes []*tree) {
+	if root == nil {
+		return nil
+	}
+	var stack []*tree
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n == nil {
+			t := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			t.finish(len(nodes) - 1)
+			return false
+		}
+		if _, ok := n.(*ast.CommentGroup); ok {
+			return false
+		}
+		t := &tree{node: n, label: reflect.TypeOf(n).Elem().Name(), value: nodeValue(n), index: len(nodes)}
+		if len(stack) > 0 {
+			t.parent = stack[len(stack)-1]
+			t.parent.children = append(t.parent.children, t)
+		}
+		nodes = append(nodes, t)
+		stack = append(stack, t)
+		return true
+	})
+	return nodes
+}
+
+func (t *tree) finish(last int) {
+	t.last, t.height, t.size = last, 1, 1
+	h := fnv.New64a()
+	h.Write([]byte(t.label + "\x00" + t.value + "\x00"))
+	for _, child := range t.children {
+		if child.height+1 > t.height {
+			t.height = child.height + 1
+		}
+		t.size += child.size
+		h.Write([]byte(strconv.FormatUint(child.hash, 16) + "\x00"))
+	}
+	t.hash = h.Sum64()
+}
+
+func nodeValue(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.Ident:
+		return n.Name
+	case *ast.BasicLit:
+		return n.Value
+	case *ast.BinaryExpr:
+		return n.Op.String()
+	case *ast.UnaryExpr:
+		return n.Op.String()
+	case *ast.AssignStmt:
+		return n.Tok.String()
+	case *ast.IncDecStmt:
+		return n.Tok.String()
+	case *ast.BranchStmt:
+		return n.Tok.String()
+	case *ast.GenDecl:
+		return n.Tok.String()
+	case *ast.RangeStmt:
+		return n.Tok.String()
+	case *ast.ChanType:
+		return strconv.Itoa(int(n.Dir))
+	}
+	return ""
+}
+
+// valueRange returns range of token holding value of node, it is the whole node for nodes without children.
+func valueRange(node ast.Node) (pos, end token.Pos, ok bool) {
+	tok := func(pos token.Pos, t token.Token) (token.Pos, token.Pos, bool) {
+		return pos, pos + token.Pos(len(t.String())) - 1, pos.IsValid()
+	}
+	switch n := node.(type) {
+	case *ast.BinaryExpr:
+		return tok(n.OpPos, n.Op)
+	case *ast.UnaryExpr:
+		return tok(n.OpPos, n.Op)
+	case *ast.AssignStmt:
+		return tok(n.TokPos, n.Tok)
+	case *ast.IncDecStmt:
+		return tok(n.TokPos, n.Tok)
+	case *ast.BranchStmt:
+		return tok(n.TokPos, n.Tok)
+	case *ast.GenDecl:
+		return tok(n.TokPos, n.Tok)
+	case *ast.RangeStmt:
+		return tok(n.TokPos, n.Tok)
+	case *ast.ChanType:
+		return n.Begin, n.Value.Pos() - 1, true
+	}
+	return node.Pos(), node.End() - 1, true
+}
+
+// matcher pairs nodes of old and new tree in GumTree fashion: isomorphic subtrees are matched top-down, then
+// containers sharing enough matched descendants are matched bottom-up and their remaining children recovered.
+type matcher struct {
+	old, new []*tree
+	src, dst map[*tree]*tree
+}
+
+func newMatcher(old, new ast.Node) *matcher {
+	m := &matcher{old: newTree(old), new: newTree(new), src: make(map[*tree]*tree), dst: make(map[*tree]*tree)}
+	if len(m.old) == 0 || len(m.new) == 0 {
+		return m
+	}
+	m.topDown()
+	m.bottomUp()
+	return m
+}
+
+func (m *matcher) add(a, b *tree) {
+	m.src[a], m.dst[b] = b, a
+}
+
+// addAll matches isomorphic trees a and b with all their descendants.
+func (m *matcher) addAll(a, b *tree) {
+	m.add(a, b)
+	for i := range a.children {
+		m.addAll(a.children[i], b.children[i])
+	}
+}
+
+// unmatched tells whether no node of subtree t from nodes is matched.
+func unmatched(t *tree, nodes []*tree, matched map[*tree]*tree) bool {
+	for _, n := range nodes[t.index : t.last+1] {
+		if _, ok := matched[n]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// dice is ratio of descendants of a matched with descendants of b.
+func (m *matcher) dice(a, b *tree) float64 {
+	if a == nil || b == nil || a.size+b.size == 2 {
+		return 0
+	}
+	common := 0
+	for _, d := range m.old[a.index+1 : a.last+1] {
+		if partner, ok := m.src[d]; ok && partner.index > b.index && partner.index <= b.last {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(a.size+b.size-2)
+}
+
+func peekMax(list []*tree) int {
+	height := 0
+	for _, t := range list {
+		if t.height > height {
+			height = t.height
+		}
+	}
+	return height
+}
+
+// pop removes trees of given height from list.
+func pop(list []*tree, height int) (rest, popped []*tree) {
+	for _, t := range list {
+		if t.height == height {
+			popped = append(popped, t)
+		} else {
+			rest = append(rest, t)
+		}
+	}
+	return
+}
+
+func (m *matcher) topDown() {
+	type candidate struct {
+		a, b *tree
+	}
+	var candidates []candidate
+	l1, l2 := []*tree{m.old[0]}, []*tree{m.new[0]}
+	for {
+		h1, h2 := peekMax(l1), peekMax(l2)
+		if h1 < minHeight || h2 < minHeight {
+			break
+		}
+		if h1 != h2 {
+			var popped []*tree
+			if h1 > h2 {
+				l1, popped = pop(l1, h1)
+				for _, t := range popped {
+					l1 = append(l1, t.children...)
+				}
+			} else {
+				l2, popped = pop(l2, h2)
+				for _, t := range popped {
+					l2 = append(l2, t.children...)
+				}
+			}
+			continue
+		}
+		var t1s, t2s []*tree
+		l1, t1s = pop(l1, h1)
+		l2, t2s = pop(l2, h2)
+		count := make(map[uint64]int)
+		for _, t1 := range t1s {
+			count[t1.hash]++
+		}
+		matched := make(map[*tree]bool)
+		for _, t1 := range t1s {
+			var isomorphic []*tree
+			for _, t2 := range t2s {
+				if t1.hash == t2.hash {
+					isomorphic = append(isomorphic, t2)
+				}
+			}
+			for _, t2 := range isomorphic {
+				if len(isomorphic) == 1 && count[t1.hash] == 1 {
+					m.addAll(t1, t2)
+				} else {
+					candidates = append(candidates, candidate{t1, t2})
+				}
+				matched[t1], matched[t2] = true, true
+			}
+		}
+		for _, t1 := range t1s {
+			if !matched[t1] {
+				l1 = append(l1, t1.children...)
+			}
+		}
+		for _, t2 := range t2s {
+			if !matched[t2] {
+				l2 = append(l2, t2.children...)
+			}
+		}
+	}
+
+	// ambiguous candidates are matched in order of similarity of their parents and then of their positions
+	position := func(t *tree, nodes []*tree) float64 { return float64(t.index) / float64(len(nodes)) }
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		di, dj := m.dice(ci.a.parent, ci.b.parent), m.dice(cj.a.parent, cj.b.parent)
+		if di != dj {
+			return di > dj
+		}
+		return math.Abs(position(ci.a, m.old)-position(ci.b, m.new)) <
+			math.Abs(position(cj.a, m.old)-position(cj.b, m.new))
+	})
+	for _, c := range candidates {
+		if unmatched(c.a, m.old, m.src) && unmatched(c.b, m.new, m.dst) {
+			m.addAll(c.a, c.b)
+		}
+	}
+}
+
+func postorder(t *tree, visit func(*tree)) {
+	for _, child := range t.children {
+		postorder(child, visit)
+	}
+	visit(t)
+}
+
+func (m *matcher) bottomUp() {
+	postorder(m.old[0], func(t1 *tree) {
+		if _, ok := m.src[t1]; ok || len(t1.children) == 0 {
+			return
+		}
+		if t1.parent == nil {
+			if root := m.new[0]; root.label == t1.label && m.dst[root] == nil {
+				m.add(t1, root)
+				m.recover(t1, root)
+			}
+			return
+		}
+		var best *tree
+		bestDice := minDice
+		for _, t2 := range m.containers(t1) {
+			if dice := m.dice(t1, t2); dice >= bestDice {
+				best, bestDice = t2, dice
+			}
+		}
+		if best != nil {
+			m.add(t1, best)
+			m.recover(t1, best)
+		}
+	})
+}
+
+// containers returns unmatched ancestors of partners of t descendants which have the same label as t.
+func (m *matcher) containers(t *tree) (result []*tree) {
+	seen := make(map[*tree]bool)
+	for _, d := range m.old[t.index+1 : t.last+1] {
+		partner, ok := m.src[d]
+		if !ok {
+			continue
+		}
+		for a := partner.parent; a != nil; a = a.parent {
+			if seen[a] {
+				break
+			}
+			seen[a] = true
+			if _, ok := m.dst[a]; !ok && a.label == t.label {
+				result = append(result, a)
+			}
+		}
+	}
+	return
+}
+
+// recover matches unmatched children of matched a and b, first isomorphic ones and then ones with the same label
+// in order of their longest common subsequence.
+func (m *matcher) recover(a, b *tree) {
+	for _, c1 := range a.children {
+		if !unmatched(c1, m.old, m.src) {
+			continue
+		}
+		for _, c2 := range b.children {
+			if c1.hash == c2.hash && unmatched(c2, m.new, m.dst) {
+				m.addAll(c1, c2)
+				break
+			}
+		}
+	}
+	var u1, u2 []*tree
+	for _, c1 := range a.children {
+		if _, ok := m.src[c1]; !ok {
+			u1 = append(u1, c1)
+		}
+	}
+	for _, c2 := range b.children {
+		if _, ok := m.dst[c2]; !ok {
+			u2 = append(u2, c2)
+		}
+	}
+	lengths := make([][]int, len(u1)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(u2)+1)
+	}
+	for i := len(u1) - 1; i >= 0; i-- {
+		for j := len(u2) - 1; j >= 0; j-- {
+			if u1[i].label == u2[j].label {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(u1) && j < len(u2); {
+		switch {
+		case u1[i].label == u2[j].label:
+			m.add(u1[i], u2[j])
+			m.recover(u1[i], u2[j])
+			i, j = i+1, j+1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+}
+
+// action is single operation of edit script, old is nil for inserts and new for deletes.
+type action struct {
+	kind     ActionKind
+	old, new *tree
+}
+
+// script returns edit script transforming old tree into new one. Matched node is moved when its parent is not
+// matched with parent of its partner or when it is not in the heaviest sequence of children keeping their order.
+func (m *matcher) script() (actions []action) {
+	for _, t1 := range m.old {
+		t2, ok := m.src[t1]
+		if !ok {
+			actions = append(actions, action{kind: ActionDelete, old: t1})
+			continue
+		}
+		if t1.value != t2.value {
+			actions = append(actions, action{kind: ActionUpdate, old: t1, new: t2})
+		}
+		if t1.parent != nil && t2.parent != nil && m.src[t1.parent] != t2.parent {
+			actions = append(actions, action{kind: ActionMove, old: t1, new: t2})
+		}
+		var children []*tree
+		var values, weights []int
+		for _, c1 := range t1.children {
+			if c2, ok := m.src[c1]; ok && c2.parent == t2 {
+				children = append(children, c1)
+				values, weights = append(values, c2.index), append(weights, c1.size)
+			}
+		}
+		kept := heaviestIncreasing(values, weights)
+		for i, c1 := range children {
+			if !kept[i] {
+				actions = append(actions, action{kind: ActionMove, old: c1, new: m.src[c1]})
+			}
+		}
+	}
+	for _, t2 := range m.new {
+		if _, ok := m.dst[t2]; !ok {
+			actions = append(actions, action{kind: ActionInsert, new: t2})
+		}
+	}
+	return
+}
+
+// coloring returns coloring of old tree for ModeOld and of new tree for ModeNew made from edit script.
+func (m *matcher) coloring(actions []action, mode Mode) Coloring {
+	changed, updated := make(map[*tree]bool), make(map[*tree]bool)
+	moved := make(map[*tree]*tree)
+	for _, a := range actions {
+		own, other := a.old, a.new
+		if mode == ModeNew {
+			own, other = other, own
+		}
+		if own == nil {
+			continue
+		}
+		switch a.kind {
+		case ActionInsert, ActionDelete:
+			changed[own] = true
+		case ActionUpdate:
+			updated[own] = true
+		case ActionMove:
+			moved[own] = other
+		}
+	}
+	nodes, matched := m.old, m.src
+	if mode == ModeNew {
+		nodes, matched = m.new, m.dst
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	var color func(t *tree) Coloring
+	color = func(t *tree) (coloring Coloring) {
+		if changed[t] && unmatched(t, nodes, matched) {
+			return Coloring{NewColorChange(mode.ToColor(), t.node)}
+		}
+		var inner Coloring
+		if updated[t] {
+			if pos, end, ok := valueRange(t.node); ok {
+				inner = append(inner, ColorChange{Color: mode.ToColor(), Pos: pos, End: end})
+			}
+		}
+		for _, child := range t.children {
+			inner = append(inner, color(child)...)
+		}
+		sort.Slice(inner, func(i, j int) bool { return inner[i].Pos < inner[j].Pos })
+		if changed[t] {
+			return fill(t.node, inner, mode.ToColor(), nil)
+		}
+		if partner, ok := moved[t]; ok {
+			return fill(t.node, inner, ColorMoved, newMove(mode, t.node, partner.node))
+		}
+		return inner
+	}
+	return color(nodes[0])
+}
+
+// DiffTree returns coloring of a compared with b computed from edit script of tree differencing, inserts and
+// deletes are colored with mode color, updates color changed identifier, literal or operator and moves use
+// ColorMoved. Nodes are matched from old to new version in both modes so both colorings agree.
+func DiffTree(a, b ast.Node, mode Mode) Coloring {
+	if mode == ModeNew && a == nil {
+		return Coloring{NewColorChange(mode.ToColor(), b)}
+	}
+	if mode == ModeOld && b == nil {
+		return Coloring{NewColorChange(mode.ToColor(), a)}
+	}
+	if a == nil || b == nil {
+		return nil
+	}
+	if mode == ModeNew {
+		return EditScript(b, a).Coloring(mode)
+	}
+	return EditScript(a, b).Coloring(mode)
+}
+
+// DiffTreeAlpha is DiffTree which does not color updates of local variables and parameters consistently renamed.
+func DiffTreeAlpha(a, b ast.Node, mode Mode) Coloring {
+	if a == nil || b == nil {
+		return DiffTree(a, b, mode)
+	}
+	old, new := a, b
+	if mode == ModeNew {
+		old, new = b, a
+	}
+	m, ctx := newMatcher(old, new), newContext(old, new)
+	var actions []action
+	for _, a := range m.script() {
+		if a.kind == ActionUpdate {
+			x, xOk := a.old.node.(*ast.Ident)
+			y, yOk := a.new.node.(*ast.Ident)
+			if xOk && yOk && ctx.sameIdent(x, y) {
+				continue
+			}
+		}
+		actions = append(actions, a)
+	}
+	return m.coloring(actions, mode)
+}
@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"go/ast"
+	"reflect"
+	"testing"
+)
+
+// fragments returns fragments of src colored with color, src has to be parsed with parseDecl.
+func fragments(src string, coloring Coloring, color Color) []string {
+	var result Coloring
+	for _, change := range coloring {
+		if change.Color == color {
+			result = append(result, change)
+		}
+	}
+	return colored(src, result)
+}
+
+func TestDiffTree(t *testing.T) {
+	tests := []struct {
+		name           string
+		old, new       string
+		removed, added []string
+		moved          []string
+	}{
+		{"same", "func F(x int) int { return x + 1 }", "func F(x int) int { return x + 1 }", nil, nil, nil},
+		{"rename", "func F() int { x := 1; return x }", "func F() int { y := 1; return y }", []string{"x", "x"}, []string{"y", "y"}, nil},
+		{"operator", "func F(a, b int) int { return a + b }", "func F(a, b int) int { return a - b }", []string{"+"}, []string{"-"}, nil},
+		{"assign", "func F() { x := 1; x = 2; use(x) }", "func F() { x := 1; x += 2; use(x) }", []string{"="}, []string{"+="}, nil},
+		{"added", "func F() { a(1) }", "func F() { a(1); b(2) }", nil, []string{"b(2)"}, nil},
+		{"removed", "func F() { a(1); b(2) }", "func F() { a(1) }", []string{"b(2)"}, nil, nil},
+		{"swapped", "func F() int { x := f(1, 2, 3); g(4); return x }", "func F() int { g(4); x := f(1, 2, 3); return x }", nil, nil, []string{"g(4)"}},
+		{"into block", "func F(c bool) { g(1); if c { h(2) } }", "func F(c bool) { if c { h(2); g(1) } }", nil, nil, []string{"g(1)"}},
+		{"param added", "func F(a int) int { return a * 2 }", "func F(a, b int) int { return a * b }", []string{"2"}, []string{"b", "b"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parseDecl(t, tt.old), parseDecl(t, tt.new)
+			left, right := DiffTree(a, b, ModeOld), DiffTree(b, a, ModeNew)
+			if got := fragments(tt.old, left, ColorRemoved); !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("removed = %q, want %q", got, tt.removed)
+			}
+			if got := fragments(tt.new, right, ColorNew); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("added = %q, want %q", got, tt.added)
+			}
+			if got := fragments(tt.old, left, ColorMoved); !reflect.DeepEqual(got, tt.moved) {
+				t.Errorf("moved from = %q, want %q", got, tt.moved)
+			}
+			if got := fragments(tt.new, right, ColorMoved); !reflect.DeepEqual(got, tt.moved) {
+				t.Errorf("moved to = %q, want %q", got, tt.moved)
+			}
+			for i := range left {
+				if left[i].Color == ColorMoved && left[i].Move.Key() != left[i].Pos {
+					t.Errorf("move key = %v, want %v", left[i].Move.Key(), left[i].Pos)
+				}
+			}
+		})
+	}
+	if coloring := DiffTree(nil, parseDecl(t, "func F() {}"), ModeNew); len(coloring) != 1 {
+		t.Errorf("DiffTree(nil) = %v, want whole declaration", coloring)
+	}
+}
+
+func TestDiffTreeAlpha(t *testing.T) {
+	tests := []struct {
+		name           string
+		old, new       string
+		removed, added []string
+	}{
+		{"rename", "func F(a int) int { b := a; return b }", "func F(x int) int { y := x; return y }", nil, nil},
+		{"rename and change", "func F(a int) int { return a + 1 }", "func F(x int) int { return x + 2 }", []string{"1"}, []string{"2"}},
+		{"inconsistent", "func F(a, b int) int { return a + a }", "func F(x, y int) int { return x + y }", []string{"a"}, []string{"y"}},
+		{"global", "func F() int { return a }", "func F() int { return x }", []string{"a"}, []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parseDecl(t, tt.old), parseDecl(t, tt.new)
+			left, right := DiffTreeAlpha(a, b, ModeOld), DiffTreeAlpha(b, a, ModeNew)
+			if got := fragments(tt.old, left, ColorRemoved); !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("removed = %q, want %q", got, tt.removed)
+			}
+			if got := fragments(tt.new, right, ColorNew); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("added = %q, want %q", got, tt.added)
+			}
+		})
+	}
+}
+
+var benchmarkDecls = [][2]string{
+	{
+		`func Sum(xs []int) int {
+	total := 0
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}`,
+		`func Sum(xs []int, scale int) (int, error) {
+	if scale == 0 {
+		return 0, errors.New("zero scale")
+	}
+	sum := 0
+	for i, x := range xs {
+		if x < 0 {
+			continue
+		}
+		sum += x * i
+	}
+	return sum * scale, nil
+}`,
+	},
+	{
+		`func (h *handler) Get(c echo.Context) error {
+	name := c.Param("name")
+	f, ok := h.history.Data[name]
+	if !ok {
+		return c.String(http.StatusNotFound, "not found")
+	}
+	data := map[string]interface{}{"name": name, "history": f}
+	return c.Render(http.StatusOK, "diff.html", data)
+}`,
+		`func (h *handler) Get(c echo.Context) error {
+	name, err := url.QueryUnescape(c.Param("name"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	f := h.history.Lookup(name)
+	if f == nil {
+		return c.String(http.StatusNotFound, name+" not found")
+	}
+	data := map[string]interface{}{"name": name, "history": f, "generated": f.Generated}
+	return c.Render(http.StatusOK, "diff.html", data)
+}`,
+	},
+}
+
+func BenchmarkDiff(b *testing.B) {
+	var pairs [][2]ast.Decl
+	for _, decls := range benchmarkDecls {
+		pairs = append(pairs, [2]ast.Decl{parseDecl(b, decls[0]), parseDecl(b, decls[1])})
+	}
+	engines := []struct {
+		name string
+		diff func(a, b ast.Decl, x, y string)
+	}{
+		{"ast", func(a, b ast.Decl, _, _ string) { Diff(a, b, ModeOld); Diff(b, a, ModeNew) }},
+		{"tree", func(a, b ast.Decl, _, _ string) { DiffTree(a, b, ModeOld); DiffTree(b, a, ModeNew) }},
+		{"lcs", func(_, _ ast.Decl, x, y string) { LCS(x, y, 0, ModeOld); LCS(x, y, 0, ModeNew) }},
+	}
+	for _, engine := range engines {
+		b.Run(engine.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j, pair := range pairs {
+					engine.diff(pair[0], pair[1], benchmarkDecls[j][0], benchmarkDecls[j][1])
+				}
+			}
+		})
+	}
+}
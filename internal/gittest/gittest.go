@@ -0,0 +1,25 @@
+// Package gittest builds commits for tests without a repository.
+package gittest
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Start is time of commit 0.
+var Start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Commit returns commit i with given parents made i days after Start by author with given email, hashes of later
+// commits sort after earlier ones.
+func Commit(i int, email string, parents ...*object.Commit) *object.Commit {
+	commit := &object.Commit{Hash: plumbing.NewHash(fmt.Sprintf("%040x", i+1)), Message: fmt.Sprint("commit ", i)}
+	commit.Author = object.Signature{Name: email, Email: email, When: Start.AddDate(0, 0, i)}
+	commit.Committer = commit.Author
+	for _, parent := range parents {
+		commit.ParentHashes = append(commit.ParentHashes, parent.Hash)
+	}
+	return commit
+}
@@ -0,0 +1,44 @@
+// Package historytest analyzes histories of test sources without a repository.
+package historytest
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/gittest"
+	"github.com/wookesh/gohist/objects"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Linear analyzes linear history of package p with file p.go in given versions, sources are without package clause.
+// It returns commit hashes from the oldest.
+func Linear(t *testing.T, versions ...string) (*objects.History, []string) {
+	history := objects.NewHistory()
+	var hashes []string
+	var parents []*object.Commit
+	for i, version := range versions {
+		commit := gittest.Commit(i, "a@x", parents...)
+		history.Commits[commit.Hash.String()] = commit
+		src := "package p\n" + version
+		f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			if function, ok := decl.(*ast.FuncDecl); ok {
+				history.Get("p."+function.Name.Name).AddElement(function, commit, "p.go", []byte(src),
+					objects.EquivalenceAST, false)
+			}
+		}
+		history.CheckForDeleted(commit)
+		history.DetectRenames(commit)
+		hashes = append(hashes, commit.Hash.String())
+		parents = []*object.Commit{commit}
+	}
+	for _, fh := range history.Data {
+		fh.PostProcess()
+	}
+	return history, hashes
+}
@@ -2,27 +2,102 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/wookesh/gohist/collector"
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/objects"
+	"github.com/wookesh/gohist/report"
 	"github.com/wookesh/gohist/ui"
 )
 
 var (
 	projectPath = flag.String("path", ".", "path to repo")
 	port        = flag.String("port", "8000", "port for web server")
-	start       = flag.String("start", "master", "newest commit to parse")
-	end         = flag.String("end", "", "latest commit to parse")
+	start       = flag.String("start", "HEAD", "newest commit to parse: hash, branch, remote branch, tag or relative ref like HEAD~50")
+	end         = flag.String("end", "", "oldest commit to parse, accepts the same revisions as start")
+	sinceDate   = flag.String("since", "", "parse only commits made after date (YYYY-MM-DD) when end is not set")
 	debug       = flag.Bool("debug", false, "Run debug mode")
 	simple      = flag.Bool("simple_diff", false, "Create graph using standard diff")
+	cacheDir    = flag.String("cache", "", "directory for history cache, caching is disabled when empty")
+	format      = flag.String("format", report.FormatText, "report format: text, csv or json")
+	sideBySide  = flag.Bool("side_by_side", false, "show versions in two columns instead of unified diff")
+	useLCS      = flag.Bool("lcs", false, "show text diff instead of AST diff, the same as -engine lcs")
+	engineName  = flag.String("engine", "", "diff engine used by show: ast, lcs or tree (default ast)")
+	withTests   = flag.Bool("tests", false, "analyze functions from _test.go files and link tests with functions they call")
+	alpha       = flag.Bool("alpha", false, "treat consistent renames of local variables and parameters as no change")
+	withDocs    = flag.Bool("docs", false, "parse comments and track versions of function doc comments")
+	skipGen     = flag.Bool("skip_generated", false, "do not analyze files with generated code header")
+	withGen     = flag.Bool("generated", false, "include generated code in report stats and hotspots")
+	sortBy      = flag.String("sort", "score", "sort hotspots by score, versions, lifetime, authors, churn or id")
+	pkgFilter   = flag.String("package", "", "list hotspots and ownership only from package directory and its subpackages")
+	config      = flag.String("config", "", "filter config file (default .gohist.json in repo if present)")
+	include     stringList
+	exclude     stringList
+	branches    stringList
+	left        stringList
+)
+
+func init() {
+	flag.Var(&include, "include", "analyze only files matching pattern, can be repeated")
+	flag.Var(&exclude, "exclude", "skip files matching pattern, can be repeated")
+	flag.Var(&branches, "branch", "additional revision to analyze together with start, can be repeated")
+	flag.Var(&left, "left", "email or name of author who left the project, can be repeated")
+}
+
+type stringList []string
+
+func (p *stringList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *stringList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+const (
+	cmdReport    = "report"
+	cmdShow      = "show"
+	cmdBreaking  = "breaking"
+	cmdHotspots  = "hotspots"
+	cmdOwnership = "ownership"
+	cmdBench     = "bench"
 )
 
 func main() {
-	flag.Parse()
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage:\n  %s [flags]\n  %s %s [flags]\n  %s %s [flags] <func> [commit]\n  %s %s [flags] <old> [new]\n  %s %s [flags]\n  %s %s [flags]\n  %s %s [flags]\n",
+			os.Args[0], os.Args[0], cmdReport, os.Args[0], cmdShow, os.Args[0], cmdBreaking, os.Args[0], cmdHotspots,
+			os.Args[0], cmdOwnership, os.Args[0], cmdBench)
+		flag.PrintDefaults()
+	}
+	args := os.Args[1:]
+	var command string
+	if len(args) > 0 {
+		switch args[0] {
+		case cmdReport, cmdShow, cmdBreaking, cmdHotspots, cmdOwnership, cmdBench:
+			command, args = args[0], args[1:]
+		}
+	}
+	flag.CommandLine.Parse(args)
+	if (command == cmdShow || command == cmdBreaking) && (flag.NArg() < 1 || flag.NArg() > 2) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	engine, err := diffEngine(*engineName, *useLCS)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	if *debug {
 		logrus.SetLevel(logrus.DebugLevel)
@@ -30,8 +105,6 @@ func main() {
 		logrus.SetLevel(logrus.InfoLevel)
 	}
 
-	go func() { http.ListenAndServe(":6060", nil) }()
-
 	if *projectPath == "" {
 		flag.PrintDefaults()
 		return
@@ -44,11 +117,86 @@ func main() {
 		*projectPath = absProjectPath
 	}
 
-	history, err := collector.CreateHistory(*projectPath, *start, *end, false, *simple)
+	filter, err := createFilter()
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+
+	var since time.Time
+	if *sinceDate != "" {
+		if since, err = collector.ParseDate(*sinceDate); err != nil {
+			logrus.Fatalln(err)
+		}
+	}
+
+	history, err := collector.CreateHistory(*projectPath, collector.Options{
+		Start:         *start,
+		End:           *end,
+		Branches:      branches,
+		Since:         since,
+		WithTests:     *withTests,
+		Simple:        *simple,
+		CacheDir:      *cacheDir,
+		Filter:        filter,
+		SkipGenerated: *skipGen,
+		WithDocs:      *withDocs,
+		Alpha:         *alpha,
+	})
 	if err != nil {
-		panic(err)
+		logrus.Fatalln(err)
 	}
 
+	switch command {
+	case cmdReport:
+		if err := report.New(history, *withGen).Write(os.Stdout, *format); err != nil {
+			logrus.Fatalln(err)
+		}
+		return
+	case cmdShow:
+		if err := show(os.Stdout, history, flag.Arg(0), flag.Arg(1), engine, *sideBySide, *alpha); err != nil {
+			logrus.Fatalln(err)
+		}
+		return
+	case cmdBreaking:
+		newRev := flag.Arg(1)
+		if newRev == "" {
+			newRev = *start
+		}
+		from, err := analyzedRevision(history, flag.Arg(0))
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		to, err := analyzedRevision(history, newRev)
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		if err := report.NewBreaking(history, from, to).Write(os.Stdout, *format); err != nil {
+			logrus.Fatalln(err)
+		}
+		return
+	case cmdHotspots:
+		hotspots := history.Hotspots(*pkgFilter, *withGen)
+		if err := objects.SortHotspots(hotspots, *sortBy); err != nil {
+			logrus.Fatalln(err)
+		}
+		if err := report.WriteHotspots(os.Stdout, hotspots, *format); err != nil {
+			logrus.Fatalln(err)
+		}
+		return
+	case cmdOwnership:
+		if err := report.WriteOwnership(os.Stdout, history.Ownership(*pkgFilter, left, *withGen), *format); err != nil {
+			logrus.Fatalln(err)
+		}
+		return
+	case cmdBench:
+		if err := report.WriteBenchmark(os.Stdout, report.NewBenchmark(history, *withGen), *format); err != nil {
+			logrus.Fatalln(err)
+		}
+		return
+	}
+
+	go func() { http.ListenAndServe(":6060", nil) }()
+
 	split := strings.Split(*projectPath, "/src/")
 	var repoName string
 	if len(split) >= 2 {
@@ -56,5 +204,49 @@ func main() {
 	} else {
 		repoName = *projectPath
 	}
-	ui.Run(history, repoName, *port)
+	ui.Run(history, repoName, *port, func(rev string) (string, error) {
+		return collector.ResolveRevision(*projectPath, rev)
+	})
+}
+
+// createFilter merges patterns from config file and flags, default excludes are replaced only by config file.
+func createFilter() (*collector.Filter, error) {
+	path := *config
+	if path == "" {
+		path = filepath.Join(*projectPath, ".gohist.json")
+		if _, err := os.Stat(path); err != nil {
+			path = ""
+		}
+	}
+	cfg := &collector.FilterConfig{}
+	if path != "" {
+		var err error
+		if cfg, err = collector.LoadFilterConfig(path); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Exclude == nil {
+		cfg.Exclude = collector.DefaultExclude
+	}
+	return collector.NewFilter(append(cfg.Include, include...), append(cfg.Exclude, exclude...))
+}
+
+// diffEngine returns engine with given name, lcs selects LCS when name is empty.
+func diffEngine(name string, lcs bool) (diff.Engine, error) {
+	if lcs && name == "" {
+		return diff.EngineLCS, nil
+	}
+	return diff.ParseEngine(name)
+}
+
+// analyzedRevision resolves revision and checks that its commit is part of analyzed history.
+func analyzedRevision(history *objects.History, rev string) (string, error) {
+	sha, err := collector.ResolveRevision(*projectPath, rev)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := history.Commits[sha]; !ok {
+		return "", fmt.Errorf("revision %s is not analyzed, set -end to older revision", rev)
+	}
+	return sha, nil
 }
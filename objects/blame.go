@@ -0,0 +1,133 @@
+package objects
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+
+	"github.com/wookesh/gohist/diff"
+)
+
+// BlameEntry attributes node of blamed element to version in which it was last changed.
+type BlameEntry struct {
+	Node    ast.Node
+	Element *HistoryElement
+}
+
+type Blame struct {
+	Element *HistoryElement
+	Entries []BlameEntry
+}
+
+type statementMatch struct {
+	elem, parent *HistoryElement
+}
+
+type blamer struct {
+	matches map[statementMatch]map[ast.Stmt]ast.Stmt
+}
+
+// Blame attributes declaration header and every statement of elem to version where it was introduced,
+// following matched statements through parents for as long as they stay the same.
+func (elem *HistoryElement) Blame() *Blame {
+	b := &blamer{matches: make(map[statementMatch]map[ast.Stmt]ast.Stmt)}
+	blame := &Blame{Element: elem}
+	if elem.Decl == nil {
+		return blame
+	}
+	blame.Entries = append(blame.Entries, BlameEntry{Node: elem.Decl, Element: b.origin(elem, elem.Decl)})
+	if f := elem.Func(); f != nil && f.Body != nil {
+		ast.Inspect(f.Body, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.BlockStmt:
+				return true
+			case ast.Stmt:
+				blame.Entries = append(blame.Entries, BlameEntry{Node: n, Element: b.origin(elem, n)})
+			}
+			return true
+		})
+	}
+	return blame
+}
+
+func sortedParents(elem *HistoryElement) []*HistoryElement {
+	parents := make([]*HistoryElement, 0, len(elem.Parent))
+	for _, parent := range elem.Parent {
+		if parent.Decl != nil {
+			parents = append(parents, parent)
+		}
+	}
+	if len(parents) == 0 && elem.Origin != nil {
+		parents = append(parents, elem.Origin)
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		return parents[i].Commit.Hash.String() < parents[j].Commit.Hash.String()
+	})
+	return parents
+}
+
+func (b *blamer) counterpart(elem, parent *HistoryElement, node ast.Node) ast.Node {
+	if node == elem.Decl {
+		return parent.Decl
+	}
+	key := statementMatch{elem, parent}
+	m, ok := b.matches[key]
+	if !ok {
+		m = diff.MatchStatements(elem.Decl, parent.Decl)
+		b.matches[key] = m
+	}
+	stmt, ok := m[node.(ast.Stmt)]
+	if !ok {
+		return nil
+	}
+	return stmt
+}
+
+func (b *blamer) origin(elem *HistoryElement, node ast.Node) *HistoryElement {
+	for {
+		found := false
+		for _, parent := range sortedParents(elem) {
+			prev := b.counterpart(elem, parent, node)
+			if prev != nil && diff.IsSameHeader(node, prev) {
+				elem, node, found = parent, prev, true
+				break
+			}
+		}
+		if !found {
+			return elem
+		}
+	}
+}
+
+// Lines returns version which introduced each line of blamed element text, the innermost node starting
+// before first non-blank character of line wins. Blank lines are attributed to nil.
+func (blame *Blame) Lines() []*HistoryElement {
+	if blame.Element.Decl == nil {
+		return nil
+	}
+	lines := strings.Split(blame.Element.Text, "\n")
+	result := make([]*HistoryElement, len(lines))
+	start := 0
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" {
+			pos := blame.Element.Offset + start + len(line) - len(trimmed)
+			var best *BlameEntry
+			for j := range blame.Entries {
+				entry := &blame.Entries[j]
+				if int(entry.Node.Pos()) <= pos && pos < int(entry.Node.End()) {
+					if best == nil || entry.Node.Pos() >= best.Node.Pos() && entry.Node.End() <= best.Node.End() {
+						best = entry
+					}
+				}
+			}
+			if best != nil {
+				result[i] = best.Element
+			}
+		}
+		start += len(line) + 1
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package objects
+
+import (
+	"testing"
+
+	"github.com/wookesh/gohist/internal/gittest"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestBlameLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		// lines are indexes of versions which introduced lines of the last version, -1 for blank line
+		lines []int
+	}{
+		{
+			"unchanged",
+			[]string{"func F() {\n\ta()\n}"},
+			[]int{0, 0, 0},
+		},
+		{
+			"statement inserted",
+			[]string{"func F() {\n\ta()\n\tb()\n}", "func F() {\n\ta()\n\tc()\n\tb()\n}"},
+			[]int{0, 0, 1, 0, 0},
+		},
+		{
+			"header changed",
+			[]string{"func F() {\n\ta()\n}", "func F() {\n\ta()\n\tb()\n}", "func F(x int) {\n\ta()\n\tb()\n}"},
+			[]int{2, 0, 1, 2},
+		},
+		{
+			"body of compound statement changed",
+			[]string{"func F() {\n\tif x {\n\t\ta()\n\t}\n}", "func F() {\n\tif x {\n\t\tb()\n\t}\n}"},
+			[]int{0, 0, 1, 0, 0},
+		},
+		{
+			"condition changed",
+			[]string{"func F() {\n\tif x {\n\t\ta()\n\t}\n}", "func F() {\n\tif y {\n\t\ta()\n\t}\n}"},
+			[]int{0, 1, 0, 1, 0},
+		},
+		{
+			"blank line",
+			[]string{"func F() {\n\ta()\n}", "func F() {\n\ta()\n\n\tb()\n}"},
+			[]int{0, 0, -1, 1, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := NewHistory()
+			var commits []*object.Commit
+			for i, version := range tt.versions {
+				commit := gittest.Commit(i, "a@x")
+				if i > 0 {
+					commit = gittest.Commit(i, "a@x", commits[i-1])
+				}
+				commits = append(commits, commit)
+				analyze(t, history, commit, map[string]string{"p/p.go": version})
+			}
+			elem := history.Data["p.F"].Elements[commits[len(commits)-1].Hash.String()]
+			lines := elem.Blame().Lines()
+			if len(lines) != len(tt.lines) {
+				t.Fatalf("Lines() returned %d lines, want %d", len(lines), len(tt.lines))
+			}
+			for i, want := range tt.lines {
+				if want < 0 {
+					if lines[i] != nil {
+						t.Errorf("line %d attributed to %s, want none", i, lines[i].Commit.Message)
+					}
+					continue
+				}
+				if lines[i] == nil || lines[i].Commit != commits[want] {
+					t.Errorf("line %d attributed to %v, want %s", i, lines[i], commits[want].Message)
+				}
+			}
+		})
+	}
+}
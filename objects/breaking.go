@@ -0,0 +1,75 @@
+package objects
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wookesh/gohist/diff"
+)
+
+// BreakingChange lists incompatible changes of exported function between two revisions.
+type BreakingChange struct {
+	ID      string   `json:"id"`
+	Changes []string `json:"changes"`
+}
+
+// public reports whether elem is exported function outside of test files.
+func (elem *HistoryElement) public() bool {
+	f := elem.Func()
+	return f != nil && diff.Exported(f) && !strings.HasSuffix(elem.File, "_test.go")
+}
+
+// breakingChanges returns incompatible changes of exported function from parent to elem, elem may be a deletion.
+func breakingChanges(parent, elem *HistoryElement) []string {
+	if parent == nil || !parent.public() {
+		return nil
+	}
+	if elem.Decl == nil {
+		return []string{"removed"}
+	}
+	return diff.BreakingChanges(parent.Func(), elem.Func())
+}
+
+// BreakingChanges returns exported functions live at from which were removed, renamed, moved or changed their
+// signature incompatibly at to.
+func (history *History) BreakingChanges(from, to string) (changes []BreakingChange) {
+	for _, fh := range history.Data {
+		// versions of histories fh was renamed or moved from are reported with them
+		old := fh.at(from)
+		if old == nil || !old.public() {
+			continue
+		}
+		current := fh.At(to)
+		if current == old {
+			continue
+		}
+		var reasons []string
+		if current == nil || current.Decl == nil {
+			reasons = []string{"removed"}
+			if successor, elem := fh.successorAt(to); successor != nil {
+				reasons = append([]string{fmt.Sprintf("%s to %s", fh.SuccessorLineage, successor.ID)},
+					diff.BreakingChanges(old.Func(), elem.Func())...)
+			}
+		} else {
+			reasons = breakingChanges(old, current)
+		}
+		if len(reasons) > 0 {
+			changes = append(changes, BreakingChange{ID: fh.ID, Changes: reasons})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes
+}
+
+// successorAt follows successors of fh and returns the first one live at sha with its element.
+func (fh *FunctionHistory) successorAt(sha string) (*FunctionHistory, *HistoryElement) {
+	visited := map[*FunctionHistory]bool{fh: true}
+	for successor := fh.Successor; successor != nil && !visited[successor]; successor = successor.Successor {
+		if elem := successor.at(sha); elem != nil && elem.Decl != nil {
+			return successor, elem
+		}
+		visited[successor] = true
+	}
+	return nil, nil
+}
@@ -0,0 +1,90 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/gittest"
+)
+
+func TestHistoryBreakingChanges(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "a@x", c1)
+	analyze(t, history, c0, map[string]string{"p/p.go": `
+func Same(a int) int { return a }
+func Sig(a int) int { return a }
+func Gone() { println("gone") }
+func Old(a, b int) int { return a*b + a - b + a*a - b*b }
+func private(a int) {}`})
+	analyze(t, history, c1, map[string]string{"p/p.go": `
+func Same(a int) int { return a + 1 }
+func Sig(a string) int { return len(a) }
+func New(a, b int64) int64 { return a*b + a - b + a*a - b*b }
+func private(a string) {}`})
+	analyze(t, history, c2, map[string]string{"p/p.go": `
+func Same(a int) int { return a + 1 }
+func Sig(a string) int { return len(a) }
+func New(a, b int64) int64 { return a*b + a - b + a*a - b*b }
+func private(a string) {}`})
+
+	changes := []BreakingChange{
+		{ID: "p.Gone", Changes: []string{"removed"}},
+		{ID: "p.Old", Changes: []string{"renamed to p.New", "parameter 1 retyped from int to int64",
+			"parameter 2 retyped from int to int64", "results changed from (int) to (int64)"}},
+		{ID: "p.Sig", Changes: []string{"parameter 1 retyped from int to string"}},
+	}
+	tests := []struct {
+		name     string
+		from, to string
+		changes  []BreakingChange
+	}{
+		{"next commit", c0.Hash.String(), c1.Hash.String(), changes},
+		{"successor after rename", c0.Hash.String(), c2.Hash.String(), changes},
+		{"after changes", c1.Hash.String(), c2.Hash.String(), nil},
+		{"same commit", c0.Hash.String(), c0.Hash.String(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := history.BreakingChanges(tt.from, tt.to); !reflect.DeepEqual(got, tt.changes) {
+				t.Errorf("BreakingChanges() = %+v, want %+v", got, tt.changes)
+			}
+		})
+	}
+}
+
+func TestSuccessorAt(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "a@x", c1)
+	body := "(a, b int) int { return a*b + a - b + a*a - b*b }"
+	analyze(t, history, c0, map[string]string{"p/p.go": "func A" + body})
+	analyze(t, history, c1, map[string]string{"p/p.go": "func B" + body})
+	analyze(t, history, c2, map[string]string{"p/p.go": "func C" + body})
+
+	tests := []struct {
+		name      string
+		sha       string
+		successor string
+	}{
+		{"before rename", c0.Hash.String(), ""},
+		{"first rename", c1.Hash.String(), "p.B"},
+		{"chained renames", c2.Hash.String(), "p.C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			successor, elem := history.Data["p.A"].successorAt(tt.sha)
+			if tt.successor == "" {
+				if successor != nil {
+					t.Errorf("successorAt() = %s, want none", successor.ID)
+				}
+				return
+			}
+			if successor == nil || successor.ID != tt.successor || elem != successor.Elements[tt.sha] {
+				t.Errorf("successorAt() = %v, %v, want %s", successor, elem, tt.successor)
+			}
+		})
+	}
+}
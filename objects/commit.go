@@ -0,0 +1,96 @@
+package objects
+
+import (
+	"sort"
+)
+
+type ChangeType int
+
+const (
+	ChangeAdded ChangeType = iota
+	ChangeModified
+	ChangeRemoved
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeAdded:
+		return "added"
+	case ChangeModified:
+		return "modified"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return ""
+	}
+}
+
+// Change is version of declaration created in commit, Parent is version it is compared with.
+type Change struct {
+	Kind    string
+	Type    ChangeType
+	History *FunctionHistory
+	Element *HistoryElement
+	Parent  *HistoryElement
+}
+
+var kindOrder = map[string]int{"function": 0, "type": 1, "variable": 2}
+
+// CommitChanges returns declarations added, modified and removed in commit with given sha. Removal of function
+// renamed or moved in the same commit is reported only as modification of its successor.
+func (history *History) CommitChanges(sha string) (changes []Change) {
+	add := func(kind string, fh *FunctionHistory) {
+		elem, ok := fh.Elements[sha]
+		if !ok {
+			return
+		}
+		parent := elem.comparedParent()
+		change := Change{Kind: kind, History: fh, Element: elem, Parent: parent}
+		switch {
+		case elem.Decl == nil:
+			if fh.Successor != nil && fh.Successor.Elements[sha] != nil {
+				return
+			}
+			change.Type = ChangeRemoved
+		case !elem.New:
+			return
+		case parent == nil:
+			change.Type = ChangeAdded
+		default:
+			change.Type = ChangeModified
+		}
+		changes = append(changes, change)
+	}
+	for _, fh := range history.Data {
+		add("function", fh)
+	}
+	for _, th := range history.Types {
+		add("type", th.FunctionHistory)
+	}
+	for _, vh := range history.Variables {
+		add("variable", vh.FunctionHistory)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return kindOrder[changes[i].Kind] < kindOrder[changes[j].Kind]
+		}
+		return changes[i].History.ID < changes[j].History.ID
+	})
+	return changes
+}
+
+// comparedParent returns the first parent, ordered by sha, which is not a deletion, or version elem was renamed or
+// moved from.
+func (elem *HistoryElement) comparedParent() *HistoryElement {
+	shas := make([]string, 0, len(elem.Parent))
+	for sha := range elem.Parent {
+		shas = append(shas, sha)
+	}
+	sort.Strings(shas)
+	for _, sha := range shas {
+		if parent := elem.Parent[sha]; parent.Decl != nil {
+			return parent
+		}
+	}
+	return elem.Origin
+}
@@ -0,0 +1,78 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/gittest"
+)
+
+func TestCommitChanges(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "a@x", c1)
+	old := "(a, b int) int { return a*b + a - b + a*a - b*b }\n"
+	analyze(t, history, c0, map[string]string{"p/p.go": "func F() { f() }\nfunc G() {}\nfunc U() {}\nfunc Old" + old})
+	analyze(t, history, c1, map[string]string{"p/p.go": "func F() { g() }\nfunc H() {}\nfunc U() {}\nfunc New" + old})
+	analyze(t, history, c2, map[string]string{"p/p.go": "func F() { g() }\nfunc G() {}\nfunc H() {}\nfunc U() {}\nfunc New" + old})
+
+	type change struct {
+		id     string
+		typ    ChangeType
+		parent string
+	}
+	tests := []struct {
+		name    string
+		sha     string
+		changes []change
+	}{
+		{"initial", c0.Hash.String(), []change{
+			{"p.F", ChangeAdded, ""},
+			{"p.G", ChangeAdded, ""},
+			{"p.Old", ChangeAdded, ""},
+			{"p.U", ChangeAdded, ""},
+		}},
+		{"modified, removed and renamed", c1.Hash.String(), []change{
+			{"p.F", ChangeModified, "p.F"},
+			{"p.G", ChangeRemoved, "p.G"},
+			{"p.H", ChangeAdded, ""},
+			{"p.New", ChangeModified, "p.Old"},
+		}},
+		{"added again", c2.Hash.String(), []change{
+			{"p.G", ChangeAdded, ""},
+		}},
+		{"unknown", "unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changes []change
+			for _, c := range history.CommitChanges(tt.sha) {
+				got := change{id: c.History.ID, typ: c.Type}
+				if c.Parent != nil {
+					got.parent = historyID(history, c.Parent)
+					if c.Parent.Commit != c0 {
+						t.Errorf("%s compared with version of %s, want %s", got.id, c.Parent.Commit.Hash, c0.Hash)
+					}
+				}
+				if c.Kind != "function" {
+					t.Errorf("%s kind = %s, want function", got.id, c.Kind)
+				}
+				changes = append(changes, got)
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("CommitChanges() = %+v, want %+v", changes, tt.changes)
+			}
+		})
+	}
+}
+
+// historyID returns ID of function history containing elem.
+func historyID(history *History, elem *HistoryElement) string {
+	for id, fh := range history.Data {
+		if fh.Elements[elem.Commit.Hash.String()] == elem {
+			return id
+		}
+	}
+	return ""
+}
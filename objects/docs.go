@@ -0,0 +1,133 @@
+package objects
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+
+	"github.com/wookesh/gohist/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// DocVersion is version of function doc comment, Text is empty when function has no doc comment.
+type DocVersion struct {
+	Commit *object.Commit
+	Text   string
+	// Parent is version doc was changed from, nil for the first version
+	Parent *DocVersion
+}
+
+// DocText returns doc comment of declaration with comment markers, it is empty when there is none.
+func DocText(decl ast.Decl) string {
+	var doc *ast.CommentGroup
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		doc = d.Doc
+	case *ast.GenDecl:
+		doc = d.Doc
+	}
+	if doc == nil {
+		return ""
+	}
+	lines := make([]string, 0, len(doc.List))
+	for _, comment := range doc.List {
+		lines = append(lines, comment.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AddDoc records doc comment of fh present in commit, new version is created only when doc differs from docs
+// in all parent commits.
+func (fh *FunctionHistory) AddDoc(doc string, commit *object.Commit) {
+	fh.m.Lock()
+	defer fh.m.Unlock()
+	if fh.docMapping == nil {
+		fh.docMapping = make(map[string]*DocVersion)
+	}
+	var parent *DocVersion
+	for _, hash := range commit.ParentHashes {
+		version, ok := fh.docMapping[hash.String()]
+		if !ok {
+			continue
+		}
+		if version.Text == doc {
+			fh.docMapping[commit.Hash.String()] = version
+			return
+		}
+		if parent == nil {
+			parent = version
+		}
+	}
+	version := &DocVersion{Commit: commit, Text: doc, Parent: parent}
+	fh.Docs = append(fh.Docs, version)
+	fh.docMapping[commit.Hash.String()] = version
+}
+
+// Diff returns line colorings of compared doc and version, offsets start at 0.
+func (version *DocVersion) Diff(compared *DocVersion) (left, right diff.Coloring) {
+	var text string
+	if compared != nil {
+		text = compared.Text
+	}
+	return diff.LCS(text, version.Text, 0, diff.ModeOld), diff.LCS(text, version.Text, 0, diff.ModeNew)
+}
+
+// DocAt returns doc version present in commit with given sha, it is nil when doc was not tracked there. Histories
+// fh was renamed or moved from are searched when fh did not exist yet.
+func (fh *FunctionHistory) DocAt(sha string) *DocVersion {
+	if version := fh.docMapping[sha]; version != nil || fh.Origin == nil {
+		return version
+	}
+	return fh.Origin.DocAt(sha)
+}
+
+// SortedDocs returns doc versions ordered by commit date, versions of histories fh was renamed or moved from
+// are included.
+func (fh *FunctionHistory) SortedDocs() []*DocVersion {
+	var docs []*DocVersion
+	for h := fh; h != nil; h = h.Origin {
+		docs = append(docs, h.Docs...)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		ti, tj := docs[i].Commit.Author.When, docs[j].Commit.Author.When
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return docs[i].Commit.Hash.String() < docs[j].Commit.Hash.String()
+	})
+	return docs
+}
+
+// DocChanges counts doc versions which changed existing doc comment or added one, doc kept by rename or move is
+// not a change.
+func (fh *FunctionHistory) DocChanges() int {
+	changes := 0
+	for _, version := range fh.Docs {
+		if version.Parent != nil && version.Parent.Text != version.Text {
+			changes++
+		}
+	}
+	return changes
+}
+
+// linkDocs continues doc history of removed function with doc of appeared one, the first doc version of appeared
+// function gets version of removed function in one of parent commits as its parent.
+func linkDocs(removed, appeared lineageCandidate) {
+	var parent *DocVersion
+	removed.history.m.Lock()
+	for _, hash := range appeared.element.Commit.ParentHashes {
+		if parent = removed.history.docMapping[hash.String()]; parent != nil {
+			break
+		}
+	}
+	removed.history.m.Unlock()
+	if parent == nil {
+		return
+	}
+	appeared.history.m.Lock()
+	version := appeared.history.docMapping[appeared.element.Commit.Hash.String()]
+	if version != nil && version.Parent == nil {
+		version.Parent = parent
+	}
+	appeared.history.m.Unlock()
+}
@@ -0,0 +1,113 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/gittest"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestAddDoc(t *testing.T) {
+	tests := []struct {
+		name string
+		// docs are doc comments of F in commits c0, c1 and c2 branching from c0 and c3 merging c1 and c2
+		docs    [4]string
+		count   int
+		changes int
+		// mergedFrom is index of commit sharing doc version with merge, -1 when merge creates new version
+		mergedFrom int
+	}{
+		{"unchanged across merge", [4]string{"// F.", "// F.", "// F.", "// F."}, 1, 0, 0},
+		{"changed in one branch", [4]string{"// F.", "// F v2.", "// F.", "// F v2."}, 2, 1, 1},
+		{"changed in both branches", [4]string{"// F.", "// F a.", "// F b.", "// F ab."}, 4, 3, -1},
+		{"added", [4]string{"", "// F.", "", "// F."}, 2, 1, 1},
+		{"removed", [4]string{"// F.", "", "// F.", ""}, 2, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := NewHistory()
+			resolver := &testResolver{commits: history.Commits, files: make(map[string]map[string]string)}
+			c0 := gittest.Commit(0, "a@x")
+			c1 := gittest.Commit(1, "a@x", c0)
+			c2 := gittest.Commit(2, "b@x", c0)
+			c3 := gittest.Commit(3, "a@x", c1, c2)
+			commits := []*object.Commit{c0, c1, c2, c3}
+			for i, commit := range commits {
+				files := map[string]string{"p/p.go": tt.docs[i] + "\nfunc F() { f() }"}
+				resolver.files[commit.Hash.String()] = files
+				analyze(t, history, commit, files)
+			}
+
+			restored, err := Restore(history.Snapshot(), resolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, h := range map[string]*History{"analyzed": history, "restored": restored} {
+				fh := h.Data["p.F"]
+				if len(fh.Docs) != tt.count || fh.DocChanges() != tt.changes {
+					t.Errorf("%s history has %d docs with %d changes, want %d with %d", name, len(fh.Docs),
+						fh.DocChanges(), tt.count, tt.changes)
+				}
+				for i, commit := range commits {
+					if doc := fh.DocAt(commit.Hash.String()); doc == nil || doc.Text != tt.docs[i] {
+						t.Errorf("%s doc at commit %d = %+v, want %q", name, i, doc, tt.docs[i])
+					}
+				}
+				merged := fh.DocAt(c3.Hash.String())
+				if tt.mergedFrom >= 0 && merged != fh.DocAt(commits[tt.mergedFrom].Hash.String()) {
+					t.Errorf("%s doc at merge is not version from commit %d", name, tt.mergedFrom)
+				}
+				if tt.mergedFrom < 0 && (merged.Commit.Hash != c3.Hash || merged.Parent == nil) {
+					t.Errorf("%s doc at merge = %+v, want new version with parent", name, merged)
+				}
+			}
+		})
+	}
+}
+
+func TestAddDocRename(t *testing.T) {
+	history := NewHistory()
+	resolver := &testResolver{commits: history.Commits, files: make(map[string]map[string]string)}
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "a@x", c1)
+	commits := []*object.Commit{c0, c1, c2}
+	sources := []string{
+		"// Old does.\nfunc Old(a, b int) int { return a*b + a - b }",
+		"// Old does.\nfunc New(a, b int) int { return a*b + a - b }",
+		"// New does.\nfunc New(a, b int) int { return a*b + a - b }",
+	}
+	for i, commit := range commits {
+		files := map[string]string{"p/p.go": sources[i]}
+		resolver.files[commit.Hash.String()] = files
+		analyze(t, history, commit, files)
+	}
+
+	restored, err := Restore(history.Snapshot(), resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, h := range map[string]*History{"analyzed": history, "restored": restored} {
+		old, fh := h.Data["p.Old"], h.Data["p.New"]
+		if fh.Origin != old {
+			t.Fatalf("%s p.New origin = %v, want p.Old", name, fh.Origin)
+		}
+		if fh.DocChanges() != 1 {
+			t.Errorf("%s p.New has %d doc changes, want 1", name, fh.DocChanges())
+		}
+		if doc := fh.DocAt(c0.Hash.String()); doc != old.DocAt(c0.Hash.String()) {
+			t.Errorf("%s p.New doc before rename = %+v, want doc of p.Old", name, doc)
+		}
+		if doc := fh.DocAt(c1.Hash.String()); doc == nil || doc.Parent != old.DocAt(c0.Hash.String()) {
+			t.Errorf("%s p.New doc at rename = %+v, want child of p.Old doc", name, doc)
+		}
+		var texts []string
+		for _, version := range fh.SortedDocs() {
+			texts = append(texts, version.Text)
+		}
+		if want := []string{"// Old does.", "// Old does.", "// New does."}; !reflect.DeepEqual(texts, want) {
+			t.Errorf("%s p.New docs = %q, want %q", name, texts, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package objects
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/util"
+)
+
+// Hotspot is function ranked by how often, by how many authors and how much it was changed.
+type Hotspot struct {
+	ID       string  `json:"id"`
+	Package  string  `json:"package"`
+	Versions int     `json:"versions"`
+	LifeTime int     `json:"lifetime"`
+	Authors  int     `json:"authors"`
+	Churn    int     `json:"churn"`
+	Score    float64 `json:"score"`
+}
+
+// HotspotSortKeys are columns hotspots can be sorted by.
+var HotspotSortKeys = []string{"score", "versions", "lifetime", "authors", "churn", "id"}
+
+// Hotspots scores existing functions from package pkg and its subpackages, all functions are scored when pkg is
+// empty. Score from 0 to 100 is average of changes, authors and churn relative to their maximum and of changes per
+// commit of function lifetime. Churn is sum of AST size differences between versions and their parents. Histories
+// functions were renamed or moved from are counted with their successors, versions moved without change are not
+// counted as authorship or churn.
+func (history *History) Hotspots(pkg string, withGenerated bool) []Hotspot {
+	hotspots := make([]Hotspot, 0, len(history.Data))
+	for id, fh := range history.Data {
+		if fh.Deleted || fh.Successor != nil || (fh.Generated && !withGenerated) {
+			continue
+		}
+		hotspot := Hotspot{ID: id, Package: fh.Package()}
+		if !inPackage(hotspot.Package, pkg) {
+			continue
+		}
+		authors := make(map[string]bool)
+		for h := fh; h != nil; h = h.Origin {
+			hotspot.Versions += h.VersionsCount()
+			hotspot.LifeTime += h.LifeTime
+			for _, elem := range h.Elements {
+				if !elem.introduces() || elem.Decl == nil {
+					continue
+				}
+				authors[elem.Commit.Author.Email] = true
+				if parent := elem.comparedParent(); parent != nil {
+					hotspot.Churn += util.IntAbs(diff.Size(elem.Decl) - diff.Size(parent.Decl))
+				}
+			}
+		}
+		hotspot.Authors = len(authors)
+		hotspots = append(hotspots, hotspot)
+	}
+	scoreHotspots(hotspots)
+	return hotspots
+}
+
+// FilterHotspots returns hotspots from package pkg and its subpackages scored relative to each other, like
+// Hotspots called with pkg would.
+func FilterHotspots(hotspots []Hotspot, pkg string) []Hotspot {
+	filtered := make([]Hotspot, 0, len(hotspots))
+	for _, hotspot := range hotspots {
+		if inPackage(hotspot.Package, pkg) {
+			filtered = append(filtered, hotspot)
+		}
+	}
+	scoreHotspots(filtered)
+	return filtered
+}
+
+// inPackage reports whether package dir is pkg or its subpackage, every package is in empty pkg.
+func inPackage(dir, pkg string) bool {
+	return pkg == "" || dir == pkg || strings.HasPrefix(dir, pkg+"/")
+}
+
+// scoreHotspots scores hotspots relative to maximums among them and sorts them by score.
+func scoreHotspots(hotspots []Hotspot) {
+	maxChanges, maxAuthors, maxChurn := 1, 1, 1
+	for _, h := range hotspots {
+		maxChanges = util.IntMax(maxChanges, h.Versions-1)
+		maxAuthors = util.IntMax(maxAuthors, h.Authors)
+		maxChurn = util.IntMax(maxChurn, h.Churn)
+	}
+	for i := range hotspots {
+		h := &hotspots[i]
+		changes := float64(h.Versions - 1)
+		h.Score = 25 * (changes/float64(maxChanges) + float64(h.Authors)/float64(maxAuthors) +
+			float64(h.Churn)/float64(maxChurn) + changes/float64(util.IntMax(h.LifeTime, 1)))
+	}
+	SortHotspots(hotspots, "score")
+}
+
+// SortHotspots sorts hotspots by one of HotspotSortKeys, descending for numbers and ascending for id.
+func SortHotspots(hotspots []Hotspot, key string) error {
+	var less func(a, b *Hotspot) bool
+	switch key {
+	case "score":
+		less = func(a, b *Hotspot) bool { return a.Score > b.Score }
+	case "versions":
+		less = func(a, b *Hotspot) bool { return a.Versions > b.Versions }
+	case "lifetime":
+		less = func(a, b *Hotspot) bool { return a.LifeTime > b.LifeTime }
+	case "authors":
+		less = func(a, b *Hotspot) bool { return a.Authors > b.Authors }
+	case "churn":
+		less = func(a, b *Hotspot) bool { return a.Churn > b.Churn }
+	case "id":
+		less = func(a, b *Hotspot) bool { return false }
+	default:
+		return fmt.Errorf("unknown sort key: %s", key)
+	}
+	sort.SliceStable(hotspots, func(i, j int) bool {
+		if less(&hotspots[i], &hotspots[j]) {
+			return true
+		}
+		if less(&hotspots[j], &hotspots[i]) {
+			return false
+		}
+		return hotspots[i].ID < hotspots[j].ID
+	})
+	return nil
+}
+
+// Package returns directory of the file with the latest version of fh.
+func (fh *FunctionHistory) Package() string {
+	if latest := fh.latest(); latest != nil {
+		return path.Dir(latest.File)
+	}
+	return path.Dir(fh.Last.File)
+}
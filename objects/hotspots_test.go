@@ -0,0 +1,167 @@
+package objects
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/internal/gittest"
+	"github.com/wookesh/gohist/util"
+)
+
+func TestHotspots(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "b@x", c0)
+	c2 := gittest.Commit(2, "a@x", c1)
+	analyze(t, history, c0, map[string]string{
+		"a/a.go":   "func F() { f() }",
+		"a/b/b.go": "func G() { g() }",
+		"ab/ab.go": "func H() { h() }",
+	})
+	analyze(t, history, c1, map[string]string{
+		"a/a.go":   "func F() { f(); f() }",
+		"a/b/b.go": "func G() { g() }",
+		"ab/ab.go": "func H() { h(); h(); h() }",
+	})
+	analyze(t, history, c2, map[string]string{
+		"a/a.go":   "func F() { f(); f(); f() }",
+		"a/b/b.go": "func G() { g(x) }",
+		"ab/ab.go": "func H() { h(); h(); h() }",
+	})
+	for _, fh := range history.Data {
+		fh.PostProcess()
+	}
+
+	tests := []struct {
+		name string
+		pkg  string
+		ids  []string
+	}{
+		{"all", "", []string{"a.F", "ab.H", "a/b.G"}},
+		{"package and subpackages", "a", []string{"a.F", "a/b.G"}},
+		{"subpackage", "a/b", []string{"a/b.G"}},
+		{"prefix of other package", "ab", []string{"ab.H"}},
+		{"unknown", "c", nil},
+	}
+	all := history.Hotspots("", false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hotspots := history.Hotspots(tt.pkg, false)
+			var ids []string
+			for _, h := range hotspots {
+				ids = append(ids, h.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("Hotspots(%q) = %v, want %v", tt.pkg, ids, tt.ids)
+			}
+			maxChanges, maxAuthors, maxChurn := 1, 1, 1
+			for _, h := range hotspots {
+				maxChanges = util.IntMax(maxChanges, h.Versions-1)
+				maxAuthors = util.IntMax(maxAuthors, h.Authors)
+				maxChurn = util.IntMax(maxChurn, h.Churn)
+			}
+			for _, h := range hotspots {
+				changes := float64(h.Versions - 1)
+				want := 25 * (changes/float64(maxChanges) + float64(h.Authors)/float64(maxAuthors) +
+					float64(h.Churn)/float64(maxChurn) + changes/float64(util.IntMax(h.LifeTime, 1)))
+				if math.Abs(h.Score-want) > 1e-9 || h.Score < 0 || h.Score > 100 {
+					t.Errorf("%s score = %v, want %v", h.ID, h.Score, want)
+				}
+			}
+			if filtered := FilterHotspots(all, tt.pkg); !reflect.DeepEqual(filtered, hotspots) {
+				t.Errorf("FilterHotspots(%q) = %+v, want %+v", tt.pkg, filtered, hotspots)
+			}
+		})
+	}
+	if f := all[0]; f.ID != "a.F" || f.Versions != 3 || f.Authors != 2 || f.Churn == 0 {
+		t.Errorf("top hotspot = %+v, want a.F with 3 versions by 2 authors", f)
+	}
+}
+
+func TestHotspotsRename(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "b@x", c0)
+	c2 := gittest.Commit(2, "a@x", c1)
+	c3 := gittest.Commit(3, "a@x", c2)
+	body := "(a, b int) int { return a*b + a - b + a*a - b*b"
+	analyze(t, history, c0, map[string]string{"p/p.go": "func Old" + body + " }\nfunc G() {}"})
+	analyze(t, history, c1, map[string]string{"p/p.go": "func Old" + body + " + 1 }\nfunc G() {}"})
+	analyze(t, history, c2, map[string]string{"p/p.go": "func New" + body + " + 1 }\nfunc G() {}"})
+	analyze(t, history, c3, map[string]string{"p/p.go": "func New" + body + " + 1 }"})
+	for _, fh := range history.Data {
+		fh.PostProcess()
+	}
+
+	hotspots := history.Hotspots("", false)
+	if len(hotspots) != 1 {
+		t.Fatalf("Hotspots() = %+v, want only p.New", hotspots)
+	}
+	old := history.Data["p.Old"]
+	elem := old.Elements[c1.Hash.String()]
+	churn := util.IntAbs(diff.Size(elem.Decl) - diff.Size(old.Elements[c0.Hash.String()].Decl))
+	if h := hotspots[0]; h.ID != "p.New" || h.Versions != 3 || h.LifeTime != 4 || h.Authors != 2 || h.Churn != churn {
+		t.Errorf("hotspot = %+v, want p.New with 3 versions in 4 commits by 2 authors and churn %d", h, churn)
+	}
+}
+
+func TestHotspotsUnchangedMove(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "b@x", c1)
+	body := "(a, b int) int { return a*b + a - b + a*a - b*b"
+	analyze(t, history, c0, map[string]string{"p/p.go": "func F" + body + " }"})
+	analyze(t, history, c1, map[string]string{"p/p.go": "func F" + body + " + 1 }"})
+	analyze(t, history, c2, map[string]string{"q/q.go": "func F" + body + " + 1 }"})
+	for _, fh := range history.Data {
+		fh.PostProcess()
+	}
+
+	hotspots := history.Hotspots("", false)
+	if len(hotspots) != 1 {
+		t.Fatalf("Hotspots() = %+v, want only q.F", hotspots)
+	}
+	old := history.Data["p.F"]
+	churn := util.IntAbs(diff.Size(old.Elements[c1.Hash.String()].Decl) - diff.Size(old.Elements[c0.Hash.String()].Decl))
+	if h := hotspots[0]; h.ID != "q.F" || h.Authors != 1 || h.Churn != churn {
+		t.Errorf("hotspot = %+v, want q.F by 1 author with churn %d", h, churn)
+	}
+}
+
+func TestSortHotspots(t *testing.T) {
+	hotspots := []Hotspot{
+		{ID: "c", Score: 50, Versions: 2, Churn: 1},
+		{ID: "b", Score: 50, Versions: 3, Churn: 1},
+		{ID: "a", Score: 10, Versions: 3, Churn: 5},
+	}
+	tests := []struct {
+		key string
+		ids []string
+	}{
+		{"score", []string{"b", "c", "a"}},
+		{"versions", []string{"a", "b", "c"}},
+		{"churn", []string{"a", "b", "c"}},
+		{"id", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			sorted := append([]Hotspot{}, hotspots...)
+			if err := SortHotspots(sorted, tt.key); err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, h := range sorted {
+				ids = append(ids, h.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("SortHotspots(%s) = %v, want %v", tt.key, ids, tt.ids)
+			}
+		})
+	}
+	if err := SortHotspots(hotspots, "unknown"); err == nil {
+		t.Error("SortHotspots(unknown) did not fail")
+	}
+}
@@ -0,0 +1,171 @@
+package objects
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+	"sort"
+
+	"github.com/wookesh/gohist/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const renameThreshold = 0.8
+
+type Lineage int
+
+const (
+	LineageNone Lineage = iota
+	LineageRenamed
+	LineageMoved
+	LineageReceiver
+)
+
+func (l Lineage) String() string {
+	switch l {
+	case LineageRenamed:
+		return "renamed"
+	case LineageMoved:
+		return "moved"
+	case LineageReceiver:
+		return "receiver changed"
+	default:
+		return ""
+	}
+}
+
+type lineageCandidate struct {
+	history *FunctionHistory
+	element *HistoryElement
+}
+
+type lineagePair struct {
+	removed, appeared lineageCandidate
+	score             float64
+}
+
+// DetectRenames links functions removed in given commit with similar functions that appeared in it.
+func (history *History) DetectRenames(commit *object.Commit) {
+	history.m.Lock()
+	defer history.m.Unlock()
+
+	sha := commit.Hash.String()
+	var removed, appeared []lineageCandidate
+	for _, fh := range history.Data {
+		fh.m.Lock()
+		elem, ok := fh.Elements[sha]
+		if ok {
+			if elem.Decl == nil {
+				if parent := elem.comparedParent(); parent != nil {
+					removed = append(removed, lineageCandidate{fh, parent})
+				}
+			} else if len(elem.Parent) == 0 && fh.Origin == nil {
+				appeared = append(appeared, lineageCandidate{fh, elem})
+			}
+		}
+		fh.m.Unlock()
+	}
+	if len(removed) == 0 || len(appeared) == 0 {
+		return
+	}
+
+	sortCandidates(removed)
+	sortCandidates(appeared)
+	var pairs []lineagePair
+	for _, r := range removed {
+		for _, a := range appeared {
+			score := diff.Similarity(r.element.Decl, a.element.Decl)
+			if score >= renameThreshold {
+				pairs = append(pairs, lineagePair{r, a, score})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].score > pairs[j].score })
+
+	used := make(map[*FunctionHistory]bool)
+	for _, pair := range pairs {
+		if used[pair.removed.history] || used[pair.appeared.history] {
+			continue
+		}
+		used[pair.removed.history] = true
+		used[pair.appeared.history] = true
+		link(pair.removed, pair.appeared)
+	}
+}
+
+// sortCandidates orders candidates by history id, so pairs with equal score are linked in the same order every run.
+func sortCandidates(candidates []lineageCandidate) {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].history.ID < candidates[j].history.ID })
+}
+
+// lineageOf tells how appeared function relates to the removed one, change of package takes precedence over rename,
+// which takes precedence over change of receiver. Functions with the same name and receiver moved to another file.
+func lineageOf(removed, appeared *HistoryElement) Lineage {
+	switch {
+	case path.Dir(removed.File) != path.Dir(appeared.File):
+		return LineageMoved
+	case removed.Func().Name.Name != appeared.Func().Name.Name:
+		return LineageRenamed
+	case receiverName(removed.Func()) != receiverName(appeared.Func()):
+		return LineageReceiver
+	default:
+		return LineageMoved
+	}
+}
+
+// receiverName returns name of receiver base type, it is empty for functions.
+func receiverName(f *ast.FuncDecl) string {
+	if f.Recv == nil || len(f.Recv.List) == 0 {
+		return ""
+	}
+	t := f.Recv.List[0].Type
+	for {
+		switch x := t.(type) {
+		case *ast.StarExpr:
+			t = x.X
+		case *ast.ParenExpr:
+			t = x.X
+		case *ast.IndexExpr:
+			t = x.X
+		case *ast.IndexListExpr:
+			t = x.X
+		case *ast.Ident:
+			return x.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// Compared returns parent of elem or version it was renamed or moved from with given commit sha.
+func (elem *HistoryElement) Compared(sha string) *HistoryElement {
+	if parent, ok := elem.Parent[sha]; ok {
+		return parent
+	}
+	if elem.Origin != nil && elem.Origin.Commit.Hash.String() == sha {
+		return elem.Origin
+	}
+	return nil
+}
+
+func link(removed, appeared lineageCandidate) {
+	lineage := lineageOf(removed.element, appeared.element)
+
+	removed.history.m.Lock()
+	removed.history.Successor = appeared.history
+	removed.history.SuccessorLineage = lineage
+	removed.history.m.Unlock()
+
+	appeared.history.m.Lock()
+	appeared.element.Origin = removed.element
+	appeared.element.Category = appeared.element.Classify(removed.element)
+	if removed.element.public() {
+		appeared.element.Breaking = append([]string{fmt.Sprintf("%s from %s", lineage, removed.history.ID)},
+			diff.BreakingChanges(removed.element.Func(), appeared.element.Func())...)
+	}
+	appeared.history.Origin = removed.history
+	appeared.history.OriginLineage = lineage
+	appeared.history.m.Unlock()
+
+	linkDocs(removed, appeared)
+}
@@ -0,0 +1,88 @@
+package objects
+
+import (
+	"testing"
+
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/internal/gittest"
+)
+
+func TestDetectRenames(t *testing.T) {
+	tests := []struct {
+		name              string
+		before, after     map[string]string
+		removed, appeared string
+		lineage           Lineage
+		category          diff.Category
+	}{
+		{
+			"rename",
+			map[string]string{"p/p.go": "func Old(a, b int) int { return a*b + a - b }"},
+			map[string]string{"p/p.go": "func New(a, b int) int { return a*b + a - b }"},
+			"p.Old", "p.New", LineageRenamed, diff.CategorySignature,
+		},
+		{
+			"receiver",
+			map[string]string{"p/p.go": "func (t T) M(a, b int) int { return a*b + a - b }"},
+			map[string]string{"p/p.go": "func (u U) M(a, b int) int { return a*b + a - b }"},
+			"p.T.M", "p.U.M", LineageReceiver, diff.CategorySignature,
+		},
+		{
+			"package",
+			map[string]string{"a/a.go": "func F(a, b int) int { return a*b + a - b }"},
+			map[string]string{"b/b.go": "func F(a, b int) int { return a*b + a - b }"},
+			"a.F", "b.F", LineageMoved, 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := NewHistory()
+			c0 := gittest.Commit(0, "a@x")
+			c1 := gittest.Commit(1, "a@x", c0)
+			analyze(t, history, c0, tt.before)
+			analyze(t, history, c1, tt.after)
+
+			removed, appeared := history.Data[tt.removed], history.Data[tt.appeared]
+			if removed == nil || appeared == nil {
+				t.Fatalf("histories %s and %s not found", tt.removed, tt.appeared)
+			}
+			if appeared.Origin != removed || appeared.OriginLineage != tt.lineage {
+				t.Errorf("origin = %v %s, want %s %s", appeared.Origin, appeared.OriginLineage, tt.removed, tt.lineage)
+			}
+			if removed.Successor != appeared || removed.SuccessorLineage != tt.lineage {
+				t.Errorf("successor = %v %s, want %s %s", removed.Successor, removed.SuccessorLineage, tt.appeared,
+					tt.lineage)
+			}
+			elem := appeared.Elements[c1.Hash.String()]
+			if len(elem.Parent) != 0 {
+				t.Errorf("appeared version has parents %v, want none", elem.Parent)
+			}
+			if elem.Origin != removed.Elements[c0.Hash.String()] {
+				t.Errorf("appeared version origin = %v, want removed version", elem.Origin)
+			}
+			if elem.Compared(c0.Hash.String()) != elem.Origin {
+				t.Errorf("Compared(%s) = %v, want origin", c0.Hash, elem.Compared(c0.Hash.String()))
+			}
+			if elem.Category != tt.category {
+				t.Errorf("appeared version category = %v, want %v", elem.Category, tt.category)
+			}
+		})
+	}
+}
+
+func TestDetectRenamesMerge(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "a@x", c0)
+	c3 := gittest.Commit(3, "a@x", c1, c2)
+	analyze(t, history, c0, map[string]string{"p/p.go": "func Old(a, b int) int { return a*b + a - b }"})
+	analyze(t, history, c1, map[string]string{"p/p.go": "func Old(a, b int) int { return a*b + a - b + 1 }"})
+	analyze(t, history, c2, map[string]string{"p/p.go": "func Old(a, b int) int { return a*b + a - b + 2 }"})
+	analyze(t, history, c3, map[string]string{"p/p.go": "func New(a, b int) int { return a*b + a - b + 3 }"})
+
+	elem := history.Data["p.New"].Elements[c3.Hash.String()]
+	if want := history.Data["p.Old"].Elements[c1.Hash.String()]; elem.Origin != want {
+		t.Errorf("origin = %v, want version of the first parent %v", elem.Origin, want)
+	}
+}
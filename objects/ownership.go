@@ -0,0 +1,156 @@
+package objects
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/wookesh/gohist/diff"
+)
+
+// AuthorShare is number of versions introduced by author and its share in all versions.
+type AuthorShare struct {
+	Name     string  `json:"name"`
+	Email    string  `json:"email"`
+	Versions int     `json:"versions"`
+	Share    float64 `json:"share"`
+}
+
+// Ownership holds authors of function versions, LastAuthor introduced the latest version.
+type Ownership struct {
+	ID         string        `json:"id"`
+	Package    string        `json:"package"`
+	Authors    []AuthorShare `json:"authors"`
+	BusFactor  int           `json:"bus_factor"`
+	LastAuthor string        `json:"last_author"`
+}
+
+type PackageOwnership struct {
+	Package   string        `json:"package"`
+	Functions int           `json:"functions"`
+	Authors   []AuthorShare `json:"authors"`
+	BusFactor int           `json:"bus_factor"`
+}
+
+// OwnershipReport aggregates authors by function and package, Orphaned are functions last changed by authors who left.
+type OwnershipReport struct {
+	Functions []Ownership        `json:"functions"`
+	Packages  []PackageOwnership `json:"packages"`
+	Orphaned  []Ownership        `json:"orphaned"`
+}
+
+// authorCounter counts versions by lowercase author email.
+type authorCounter map[string]*AuthorShare
+
+func (c authorCounter) add(name, email string, versions int) {
+	key := strings.ToLower(email)
+	if share, ok := c[key]; ok {
+		share.Versions += versions
+		return
+	}
+	c[key] = &AuthorShare{Name: name, Email: email, Versions: versions}
+}
+
+// shares returns authors ordered by number of versions and bus factor, which is the smallest number of top authors
+// who introduced at least half of all versions.
+func (c authorCounter) shares() ([]AuthorShare, int) {
+	total := 0
+	shares := make([]AuthorShare, 0, len(c))
+	for _, share := range c {
+		shares = append(shares, *share)
+		total += share.Versions
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Versions != shares[j].Versions {
+			return shares[i].Versions > shares[j].Versions
+		}
+		return shares[i].Email < shares[j].Email
+	})
+	busFactor, covered := 0, 0
+	for i := range shares {
+		shares[i].Share = float64(shares[i].Versions) / float64(total)
+		if 2*covered < total {
+			covered += shares[i].Versions
+			busFactor++
+		}
+	}
+	return shares, busFactor
+}
+
+// Ownership returns authors of existing functions from package pkg and its subpackages, all packages are used when
+// pkg is empty. Authors of histories functions were renamed or moved from are included. Authors who left are matched
+// by email or name, case insensitive.
+func (history *History) Ownership(pkg string, left []string, withGenerated bool) *OwnershipReport {
+	gone := make(map[string]bool, len(left))
+	for _, author := range left {
+		if author = strings.TrimSpace(author); author != "" {
+			gone[strings.ToLower(author)] = true
+		}
+	}
+	report := &OwnershipReport{Functions: []Ownership{}, Packages: []PackageOwnership{}, Orphaned: []Ownership{}}
+	packages := make(map[string]authorCounter)
+	functions := make(map[string]int)
+	for id, fh := range history.Data {
+		if fh.Deleted || (fh.Generated && !withGenerated) {
+			continue
+		}
+		ownership := Ownership{ID: id, Package: fh.Package()}
+		if !inPackage(ownership.Package, pkg) {
+			continue
+		}
+		last := fh.latest().introduced()
+		if last == nil {
+			continue
+		}
+		if packages[ownership.Package] == nil {
+			packages[ownership.Package] = make(authorCounter)
+		}
+		authors := make(authorCounter)
+		for h := fh; h != nil; h = h.Origin {
+			for _, elem := range h.Elements {
+				if elem.introduces() && elem.Decl != nil {
+					authors.add(elem.Commit.Author.Name, elem.Commit.Author.Email, 1)
+					packages[ownership.Package].add(elem.Commit.Author.Name, elem.Commit.Author.Email, 1)
+				}
+			}
+		}
+		functions[ownership.Package]++
+		ownership.Authors, ownership.BusFactor = authors.shares()
+		author := last.Commit.Author
+		ownership.LastAuthor = author.Email
+		report.Functions = append(report.Functions, ownership)
+		if gone[strings.ToLower(author.Email)] || gone[strings.ToLower(author.Name)] {
+			report.Orphaned = append(report.Orphaned, ownership)
+		}
+	}
+	for name, authors := range packages {
+		p := PackageOwnership{Package: name, Functions: functions[name]}
+		p.Authors, p.BusFactor = authors.shares()
+		report.Packages = append(report.Packages, p)
+	}
+	sort.Slice(report.Functions, func(i, j int) bool { return report.Functions[i].ID < report.Functions[j].ID })
+	sort.Slice(report.Orphaned, func(i, j int) bool { return report.Orphaned[i].ID < report.Orphaned[j].ID })
+	sort.Slice(report.Packages, func(i, j int) bool { return report.Packages[i].Package < report.Packages[j].Package })
+	return report
+}
+
+// introduces reports whether elem is new version of declaration, version moved without change keeps declaration of
+// version it was moved from.
+func (elem *HistoryElement) introduces() bool {
+	return elem.New && (elem.Origin == nil || elem.Origin.Text != elem.Text)
+}
+
+// introduced returns version which introduced declaration of elem, versions created by merges are followed through
+// parents with the same declaration and versions moved without change through versions they were moved from.
+func (elem *HistoryElement) introduced() *HistoryElement {
+	for elem != nil && !elem.introduces() {
+		next := elem.comparedParent()
+		for _, parent := range sortedParents(elem) {
+			if parent.Text == elem.Text || diff.IsSameAlpha(parent.Decl, elem.Decl) {
+				next = parent
+				break
+			}
+		}
+		elem = next
+	}
+	return elem
+}
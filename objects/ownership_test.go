@@ -0,0 +1,137 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/gittest"
+)
+
+func TestAuthorCounterShares(t *testing.T) {
+	type added struct {
+		email    string
+		versions int
+	}
+	tests := []struct {
+		name      string
+		added     []added
+		emails    []string
+		versions  []int
+		busFactor int
+	}{
+		{"single", []added{{"a@x", 3}}, []string{"a@x"}, []int{3}, 1},
+		{"dominant", []added{{"b@x", 1}, {"a@x", 3}, {"c@x", 1}}, []string{"a@x", "b@x", "c@x"}, []int{3, 1, 1}, 1},
+		{"even", []added{{"a@x", 2}, {"b@x", 2}, {"c@x", 2}, {"d@x", 2}}, []string{"a@x", "b@x", "c@x", "d@x"},
+			[]int{2, 2, 2, 2}, 2},
+		{"spread", []added{{"a@x", 2}, {"b@x", 1}, {"c@x", 1}, {"d@x", 1}}, []string{"a@x", "b@x", "c@x", "d@x"},
+			[]int{2, 1, 1, 1}, 2},
+		{"case insensitive", []added{{"a@x", 1}, {"A@X", 2}}, []string{"a@x"}, []int{3}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := make(authorCounter)
+			for _, a := range tt.added {
+				counter.add("", a.email, a.versions)
+			}
+			shares, busFactor := counter.shares()
+			var emails []string
+			var versions []int
+			for _, share := range shares {
+				emails = append(emails, share.Email)
+				versions = append(versions, share.Versions)
+			}
+			if !reflect.DeepEqual(emails, tt.emails) || !reflect.DeepEqual(versions, tt.versions) {
+				t.Errorf("shares() authors = %v with versions %v, want %v with %v", emails, versions, tt.emails,
+					tt.versions)
+			}
+			if busFactor != tt.busFactor {
+				t.Errorf("shares() bus factor = %d, want %d", busFactor, tt.busFactor)
+			}
+		})
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "b@x", c0)
+	c2 := gittest.Commit(2, "a@x", c0)
+	c3 := gittest.Commit(3, "c@x", c1, c2)
+	analyze(t, history, c0, map[string]string{"p/p.go": "func F() { f() }\nfunc G() { g() }\nfunc H() {}"})
+	analyze(t, history, c1, map[string]string{"p/p.go": "func F() { f(); f() }\nfunc G() { g() }"})
+	analyze(t, history, c2, map[string]string{"p/p.go": "func F() { f() }\nfunc G() { g(); g() }\nfunc H() {}"})
+	// merge takes F from c1 and G from c2
+	analyze(t, history, c3, map[string]string{"p/p.go": "func F() { f(); f() }\nfunc G() { g(); g() }"})
+	for _, fh := range history.Data {
+		fh.PostProcess()
+	}
+
+	tests := []struct {
+		name     string
+		left     []string
+		orphaned []string
+	}{
+		{"nobody left", nil, nil},
+		{"merged author", []string{" B@X "}, []string{"p.F"}},
+		{"all authors", []string{"a@x", "b@x"}, []string{"p.F", "p.G"}},
+		{"merge committer", []string{"c@x"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := history.Ownership("", tt.left, false)
+			last := make(map[string]string)
+			for _, ownership := range report.Functions {
+				last[ownership.ID] = ownership.LastAuthor
+			}
+			if want := map[string]string{"p.F": "b@x", "p.G": "a@x"}; !reflect.DeepEqual(last, want) {
+				t.Errorf("last authors = %v, want %v", last, want)
+			}
+			var orphaned []string
+			for _, ownership := range report.Orphaned {
+				orphaned = append(orphaned, ownership.ID)
+			}
+			if !reflect.DeepEqual(orphaned, tt.orphaned) {
+				t.Errorf("orphaned = %v, want %v", orphaned, tt.orphaned)
+			}
+			if len(report.Packages) != 1 || report.Packages[0].Functions != 2 {
+				t.Errorf("packages = %+v, want p with 2 functions", report.Packages)
+			}
+		})
+	}
+}
+
+func TestOwnershipRename(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "b@x", c0)
+	c2 := gittest.Commit(2, "c@x", c1)
+	analyze(t, history, c0, map[string]string{"p/p.go": "func Old(a, b int) int { return a*b + a - b + a*a - b*b }"})
+	analyze(t, history, c1, map[string]string{"p/p.go": "func New(a, b int) int { return a*b + a - b + a*a - b*b }"})
+	// unchanged move does not make c@x author
+	analyze(t, history, c2, map[string]string{"q/q.go": "func New(a, b int) int { return a*b + a - b + a*a - b*b }"})
+	for _, fh := range history.Data {
+		fh.PostProcess()
+	}
+	if fh := history.Data["q.New"]; fh.Origin == nil || fh.Origin.Origin != history.Data["p.Old"] {
+		t.Fatalf("q.New origin = %v, want p.New renamed from p.Old", fh.Origin)
+	}
+
+	report := history.Ownership("", []string{"b@x"}, false)
+	if len(report.Functions) != 1 {
+		t.Fatalf("functions = %+v, want q.New only", report.Functions)
+	}
+	ownership := report.Functions[0]
+	var emails []string
+	for _, share := range ownership.Authors {
+		emails = append(emails, share.Email)
+	}
+	if want := []string{"a@x", "b@x"}; ownership.ID != "q.New" || !reflect.DeepEqual(emails, want) {
+		t.Errorf("authors of %s = %v, want %v", ownership.ID, emails, want)
+	}
+	if ownership.LastAuthor != "b@x" {
+		t.Errorf("last author = %s, want b@x", ownership.LastAuthor)
+	}
+	if len(report.Orphaned) != 1 || report.Orphaned[0].ID != "q.New" {
+		t.Errorf("orphaned = %+v, want q.New", report.Orphaned)
+	}
+}
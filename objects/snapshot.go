@@ -0,0 +1,303 @@
+package objects
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"time"
+
+	"github.com/wookesh/gohist/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Snapshot is a serializable form of History, declarations are stored as references to files in commits.
+type Snapshot struct {
+	CommitsAnalyzed    int32
+	MaxChanged         int32
+	CountPerCommit     map[time.Time]int
+	GeneratedPerCommit map[time.Time]int
+	Errors             []AnalysisError
+	Functions          map[string]*HistorySnapshot
+	Types              map[string]*HistorySnapshot
+	Variables          map[string]*HistorySnapshot
+}
+
+type HistorySnapshot struct {
+	ID                              string
+	LifeTime                        int
+	EditLifeTime                    int
+	Deleted                         bool
+	Elements                        map[string]*ElementSnapshot
+	ParentMapping                   map[string]map[string]bool
+	Origin, Successor               string
+	OriginLineage, SuccessorLineage Lineage
+	// Docs are doc versions by commit sha, DocMapping points commits to their doc versions
+	Docs       map[string]*DocSnapshot
+	DocMapping map[string]string
+}
+
+// DocSnapshot is doc version, Parent is sha of parent version, empty for the first one. FromOrigin is set when
+// parent is version of history the function was renamed or moved from.
+type DocSnapshot struct {
+	Text       string
+	Parent     string
+	FromOrigin bool
+}
+
+type ElementSnapshot struct {
+	File      string
+	New       bool
+	Generated bool
+	Category  diff.Category
+	Breaking  []string
+	Parents   []string
+	// Origin points to version of another history the element was renamed or moved from
+	Origin *ElementRef
+}
+
+// ElementRef points to element of history with given ID.
+type ElementRef struct {
+	ID  string
+	SHA string
+}
+
+type Resolver interface {
+	Commit(sha string) (*object.Commit, error)
+	Decl(id, sha, file string) (ast.Decl, *SplitName, []byte, error)
+}
+
+func (history *History) Snapshot() *Snapshot {
+	history.m.Lock()
+	defer history.m.Unlock()
+	s := &Snapshot{
+		CommitsAnalyzed:    history.CommitsAnalyzed,
+		MaxChanged:         history.MaxChanged,
+		CountPerCommit:     make(map[time.Time]int, len(history.CountPerCommit)),
+		GeneratedPerCommit: make(map[time.Time]int, len(history.GeneratedPerCommit)),
+		Errors:             history.Errors,
+		Functions:          make(map[string]*HistorySnapshot, len(history.Data)),
+		Types:              make(map[string]*HistorySnapshot, len(history.Types)),
+		Variables:          make(map[string]*HistorySnapshot, len(history.Variables)),
+	}
+	for date, count := range history.CountPerCommit {
+		s.CountPerCommit[date] = count
+	}
+	for date, count := range history.GeneratedPerCommit {
+		s.GeneratedPerCommit[date] = count
+	}
+	for id, fh := range history.Data {
+		s.Functions[id] = fh.snapshot()
+	}
+	for id, th := range history.Types {
+		s.Types[id] = th.snapshot()
+	}
+	for id, vh := range history.Variables {
+		s.Variables[id] = vh.snapshot()
+	}
+	return s
+}
+
+func (fh *FunctionHistory) snapshot() *HistorySnapshot {
+	fh.m.Lock()
+	defer fh.m.Unlock()
+	s := &HistorySnapshot{
+		ID:               fh.ID,
+		LifeTime:         fh.LifeTime,
+		EditLifeTime:     fh.EditLifeTime,
+		Deleted:          fh.Deleted,
+		Elements:         make(map[string]*ElementSnapshot, len(fh.Elements)),
+		ParentMapping:    fh.parentMapping,
+		OriginLineage:    fh.OriginLineage,
+		SuccessorLineage: fh.SuccessorLineage,
+	}
+	if fh.Origin != nil {
+		s.Origin = fh.Origin.ID
+	}
+	if fh.Successor != nil {
+		s.Successor = fh.Successor.ID
+	}
+	for sha, elem := range fh.Elements {
+		es := &ElementSnapshot{File: elem.File, New: elem.New, Generated: elem.Generated, Category: elem.Category,
+			Breaking: elem.Breaking}
+		for parentSHA := range elem.Parent {
+			es.Parents = append(es.Parents, parentSHA)
+		}
+		sort.Strings(es.Parents)
+		if elem.Origin != nil && fh.Origin != nil {
+			es.Origin = &ElementRef{ID: fh.Origin.ID, SHA: elem.Origin.Commit.Hash.String()}
+		}
+		s.Elements[sha] = es
+	}
+	if fh.docMapping != nil {
+		s.Docs = make(map[string]*DocSnapshot, len(fh.Docs))
+		s.DocMapping = make(map[string]string, len(fh.docMapping))
+		own := make(map[*DocVersion]bool, len(fh.Docs))
+		for _, version := range fh.Docs {
+			own[version] = true
+		}
+		for _, version := range fh.Docs {
+			ds := &DocSnapshot{Text: version.Text}
+			if version.Parent != nil {
+				ds.Parent = version.Parent.Commit.Hash.String()
+				ds.FromOrigin = !own[version.Parent]
+			}
+			s.Docs[version.Commit.Hash.String()] = ds
+		}
+		for sha, version := range fh.docMapping {
+			s.DocMapping[sha] = version.Commit.Hash.String()
+		}
+	}
+	return s
+}
+
+func Restore(s *Snapshot, resolver Resolver) (*History, error) {
+	history := NewHistory()
+	history.CommitsAnalyzed = s.CommitsAnalyzed
+	history.MaxChanged = s.MaxChanged
+	history.Errors = s.Errors
+	for date, count := range s.CountPerCommit {
+		history.CountPerCommit[date] = count
+	}
+	for date, count := range s.GeneratedPerCommit {
+		history.GeneratedPerCommit[date] = count
+	}
+
+	commits := make(map[string]*object.Commit)
+	commit := func(sha string) (*object.Commit, error) {
+		if commit, ok := commits[sha]; ok {
+			return commit, nil
+		}
+		commit, err := resolver.Commit(sha)
+		if err != nil {
+			return nil, err
+		}
+		commits[sha] = commit
+		return commit, nil
+	}
+	restore := func(hs *HistorySnapshot) (*FunctionHistory, error) {
+		fh := NewFunctionHistory(hs.ID)
+		fh.LifeTime = hs.LifeTime
+		fh.EditLifeTime = hs.EditLifeTime
+		fh.Deleted = hs.Deleted
+		fh.OriginLineage = hs.OriginLineage
+		fh.SuccessorLineage = hs.SuccessorLineage
+		if hs.ParentMapping != nil {
+			fh.parentMapping = hs.ParentMapping
+		}
+		for sha, es := range hs.Elements {
+			elemCommit, err := commit(sha)
+			if err != nil {
+				return nil, err
+			}
+			elem := &HistoryElement{
+				Commit:    elemCommit,
+				File:      es.File,
+				New:       es.New,
+				Generated: es.Generated,
+				Category:  es.Category,
+				Breaking:  es.Breaking,
+				Parent:    make(map[string]*HistoryElement),
+				Children:  make(map[string]*HistoryElement),
+			}
+			if es.File != "" {
+				decl, split, body, err := resolver.Decl(hs.ID, sha, es.File)
+				if err != nil {
+					return nil, err
+				}
+				elem.Decl = decl
+				elem.Split = split
+				elem.Text = string(body[decl.Pos()-1 : decl.End()-1])
+				elem.Offset = int(decl.Pos())
+			}
+			fh.Elements[sha] = elem
+		}
+		if hs.DocMapping != nil {
+			versions := make(map[string]*DocVersion, len(hs.Docs))
+			for sha, ds := range hs.Docs {
+				docCommit, err := commit(sha)
+				if err != nil {
+					return nil, err
+				}
+				versions[sha] = &DocVersion{Commit: docCommit, Text: ds.Text}
+				fh.Docs = append(fh.Docs, versions[sha])
+			}
+			for sha, ds := range hs.Docs {
+				if !ds.FromOrigin {
+					versions[sha].Parent = versions[ds.Parent]
+				}
+			}
+			fh.docMapping = make(map[string]*DocVersion, len(hs.DocMapping))
+			for sha, versionSHA := range hs.DocMapping {
+				fh.docMapping[sha] = versions[versionSHA]
+			}
+		}
+		return fh, nil
+	}
+
+	for id, hs := range s.Functions {
+		fh, err := restore(hs)
+		if err != nil {
+			return nil, err
+		}
+		history.Data[id] = fh
+	}
+	for id, hs := range s.Types {
+		fh, err := restore(hs)
+		if err != nil {
+			return nil, err
+		}
+		history.Types[id] = &TypeHistory{FunctionHistory: fh}
+	}
+	for id, hs := range s.Variables {
+		fh, err := restore(hs)
+		if err != nil {
+			return nil, err
+		}
+		history.Variables[id] = &VariableHistory{FunctionHistory: fh}
+	}
+
+	link := func(snapshots map[string]*HistorySnapshot, histories func(id string) *FunctionHistory) error {
+		for id, hs := range snapshots {
+			fh := histories(id)
+			fh.Origin = history.Data[hs.Origin]
+			fh.Successor = history.Data[hs.Successor]
+			for sha, es := range hs.Elements {
+				elem := fh.Elements[sha]
+				for _, parentSHA := range es.Parents {
+					parent := fh.Elements[parentSHA]
+					if parent == nil {
+						return fmt.Errorf("restore: missing parent %s of %s in %s", parentSHA, sha, id)
+					}
+					elem.Parent[parentSHA] = parent
+					parent.Children[sha] = elem
+				}
+				if ref := es.Origin; ref != nil {
+					if history.Data[ref.ID] == nil || history.Data[ref.ID].Elements[ref.SHA] == nil {
+						return fmt.Errorf("restore: missing origin %s of %s in %s", ref.SHA, sha, ref.ID)
+					}
+					elem.Origin = history.Data[ref.ID].Elements[ref.SHA]
+				}
+			}
+			for sha, ds := range hs.Docs {
+				if !ds.FromOrigin {
+					continue
+				}
+				if fh.Origin == nil || fh.Origin.docMapping[ds.Parent] == nil {
+					return fmt.Errorf("restore: missing origin doc %s of %s in %s", ds.Parent, sha, id)
+				}
+				fh.docMapping[sha].Parent = fh.Origin.docMapping[ds.Parent]
+			}
+		}
+		return nil
+	}
+	if err := link(s.Functions, func(id string) *FunctionHistory { return history.Data[id] }); err != nil {
+		return nil, err
+	}
+	if err := link(s.Types, func(id string) *FunctionHistory { return history.Types[id].FunctionHistory }); err != nil {
+		return nil, err
+	}
+	if err := link(s.Variables, func(id string) *FunctionHistory { return history.Variables[id].FunctionHistory }); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
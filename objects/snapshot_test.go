@@ -0,0 +1,106 @@
+package objects
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/gittest"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// testResolver resolves declarations from sources of files passed to analyze, keyed by commit sha.
+type testResolver struct {
+	commits map[string]*object.Commit
+	files   map[string]map[string]string
+}
+
+func (r *testResolver) Commit(sha string) (*object.Commit, error) {
+	commit, ok := r.commits[sha]
+	if !ok {
+		return nil, fmt.Errorf("commit %s not found", sha)
+	}
+	return commit, nil
+}
+
+func (r *testResolver) Decl(id, sha, file string) (ast.Decl, *SplitName, []byte, error) {
+	src := testSource(file, r.files[sha][file])
+	f, err := parser.ParseFile(token.NewFileSet(), file, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, decl := range f.Decls {
+		if function, ok := decl.(*ast.FuncDecl); ok && testID(file, function) == id {
+			return function, nil, []byte(src), nil
+		}
+	}
+	return nil, nil, nil, fmt.Errorf("%s not found in %s at %s", id, file, sha)
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	history := NewHistory()
+	resolver := &testResolver{commits: history.Commits, files: make(map[string]map[string]string)}
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "b@x", c0)
+	c3 := gittest.Commit(3, "a@x", c1, c2)
+	versions := []struct {
+		commit *object.Commit
+		files  map[string]string
+	}{
+		{c0, map[string]string{"p/p.go": "// F does f.\nfunc F() { f() }\nfunc Old(a, b int) int { return a*b + a - b + a*a - b*b }"}},
+		{c1, map[string]string{"p/p.go": "// F does f twice.\nfunc F() { f(); f() }\nfunc New(a, b int) int { return a*b + a - b + a*a - b*b }"}},
+		{c2, map[string]string{"p/p.go": "// F does f.\nfunc F() { g() }\nfunc Old(a, b int) int { return a*b + a - b + a*a - b*b }"}},
+		{c3, map[string]string{"p/p.go": "// F does f twice.\nfunc F() { f(); g() }\nfunc New(a, b int) int { return a*b + a - b + a*a - b*b }"}},
+	}
+	for _, version := range versions {
+		resolver.files[version.commit.Hash.String()] = version.files
+		analyze(t, history, version.commit, version.files)
+	}
+	if history.Data["p.New"].Elements[c1.Hash.String()].Origin == nil {
+		t.Fatal("rename of p.Old to p.New not detected")
+	}
+
+	snapshot := history.Snapshot()
+	if ref := snapshot.Functions["p.New"].Elements[c1.Hash.String()].Origin; ref == nil || *ref != (ElementRef{ID: "p.Old", SHA: c0.Hash.String()}) {
+		t.Errorf("snapshot origin of p.New = %v, want version of p.Old at %s", ref, c0.Hash)
+	}
+	restored, err := Restore(snapshot, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restoredSnapshot := restored.Snapshot(); !reflect.DeepEqual(restoredSnapshot, snapshot) {
+		t.Errorf("snapshot of restored history = %+v, want %+v", restoredSnapshot, snapshot)
+	}
+	for id, fh := range history.Data {
+		restoredFH := restored.Data[id]
+		if restoredFH == nil {
+			t.Errorf("%s not restored", id)
+			continue
+		}
+		for sha, elem := range fh.Elements {
+			restoredElem := restoredFH.Elements[sha]
+			if restoredElem.Text != elem.Text || restoredElem.Offset != elem.Offset || (restoredElem.Decl == nil) != (elem.Decl == nil) {
+				t.Errorf("%s at %s restored as %q at %d, want %q at %d", id, sha, restoredElem.Text, restoredElem.Offset,
+					elem.Text, elem.Offset)
+			}
+			if len(restoredElem.Children) != len(elem.Children) {
+				t.Errorf("%s at %s restored with %d children, want %d", id, sha, len(restoredElem.Children),
+					len(elem.Children))
+			}
+			if elem.Origin != nil && restoredElem.Origin != restored.Data[fh.Origin.ID].Elements[elem.Origin.Commit.Hash.String()] {
+				t.Errorf("%s at %s restored with origin %v, want version of %s", id, sha, restoredElem.Origin, fh.Origin.ID)
+			}
+		}
+		for _, commit := range []*object.Commit{c0, c1, c2, c3} {
+			doc, restoredDoc := fh.DocAt(commit.Hash.String()), restoredFH.DocAt(commit.Hash.String())
+			if (doc == nil) != (restoredDoc == nil) || doc != nil && (doc.Text != restoredDoc.Text ||
+				doc.Commit != restoredDoc.Commit || (doc.Parent == nil) != (restoredDoc.Parent == nil)) {
+				t.Errorf("%s doc at %s restored as %+v, want %+v", id, commit.Hash, restoredDoc, doc)
+			}
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package objects
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TestKind int
+
+const (
+	TestKindNone TestKind = iota
+	TestKindTest
+	TestKindBenchmark
+	TestKindExample
+	TestKindFuzz
+)
+
+func (k TestKind) String() string {
+	switch k {
+	case TestKindTest:
+		return "test"
+	case TestKindBenchmark:
+		return "benchmark"
+	case TestKindExample:
+		return "example"
+	case TestKindFuzz:
+		return "fuzz"
+	default:
+		return ""
+	}
+}
+
+// TestKindOf classifies function declared in file using the same naming rules as go test.
+func TestKindOf(f *ast.FuncDecl, file string) TestKind {
+	if f == nil || f.Recv != nil || !strings.HasSuffix(file, "_test.go") {
+		return TestKindNone
+	}
+	name := f.Name.Name
+	switch {
+	case isTestName(name, "Test") && hasTestingParam(f, "T"):
+		return TestKindTest
+	case isTestName(name, "Benchmark") && hasTestingParam(f, "B"):
+		return TestKindBenchmark
+	case isTestName(name, "Fuzz") && hasTestingParam(f, "F"):
+		return TestKindFuzz
+	case strings.HasPrefix(name, "Example") && f.Type.Params.NumFields() == 0 && f.Type.Results.NumFields() == 0:
+		return TestKindExample
+	default:
+		return TestKindNone
+	}
+}
+
+// isTestName reports whether name is prefix followed by nothing or by a character which is not lower case.
+func isTestName(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// hasTestingParam reports whether f takes single parameter of type *testing.<typ>.
+func hasTestingParam(f *ast.FuncDecl, typ string) bool {
+	if f.Type.Params.NumFields() != 1 {
+		return false
+	}
+	star, ok := f.Type.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == typ
+}
+
+// LinkTests classifies test functions and links them with functions they call in any of their versions.
+// Calls are resolved by name: plain calls in package of the test, pkg.Func calls by package directory name
+// and method calls by method name and receiver type. Type of receiver is known only for composite literals,
+// new and variables or parameters declared with them or with explicit type.
+func (history *History) LinkTests() {
+	history.m.Lock()
+	defer history.m.Unlock()
+
+	targets := make(map[string]*FunctionHistory)
+	packages := make(map[string][]string)
+	var tests []*FunctionHistory
+	for id, fh := range history.Data {
+		fh.Test, fh.Tests, fh.Covers = TestKindNone, nil, nil
+		latest := fh.latest()
+		if latest == nil {
+			continue
+		}
+		f := latest.Func()
+		if f == nil {
+			continue
+		}
+		if fh.Test = TestKindOf(f, latest.File); fh.Test != TestKindNone {
+			tests = append(tests, fh)
+			continue
+		}
+		if strings.HasSuffix(latest.File, "_test.go") || f.Name.Name == "init" {
+			continue
+		}
+		targets[id] = fh
+		if f.Recv != nil {
+			continue
+		}
+		if dir := strings.TrimSuffix(strings.TrimSuffix(id, f.Name.Name), "."); dir != "" {
+			packages[path.Base(dir)] = append(packages[path.Base(dir)], dir+".")
+		}
+	}
+
+	for _, test := range tests {
+		prefix := strings.TrimSuffix(test.ID, test.latest().Func().Name.Name)
+		covered := make(map[*FunctionHistory]bool)
+		link := func(target *FunctionHistory) {
+			if target != nil && !covered[target] {
+				covered[target] = true
+				test.Covers = append(test.Covers, target)
+				target.Tests = append(target.Tests, test)
+			}
+		}
+		// linkPackage links target with given name in packages with directory name pkg, empty pkg means package
+		// of the test
+		linkPackage := func(pkg, name string) {
+			if pkg == "" {
+				link(targets[prefix+name])
+				return
+			}
+			for _, prefix := range packages[pkg] {
+				link(targets[prefix+name])
+			}
+		}
+		// linkMethod links method of named type, which may be qualified with package name
+		linkMethod := func(typ, name string) {
+			pkg := ""
+			if i := strings.Index(typ, "."); i >= 0 {
+				pkg, typ = typ[:i], typ[i+1:]
+			}
+			linkPackage(pkg, typ+"."+name)
+		}
+		for _, elem := range test.Elements {
+			if elem.Decl == nil {
+				continue
+			}
+			types := variableTypes(elem.Decl)
+			ast.Inspect(elem.Decl, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				fun := call.Fun
+				switch index := fun.(type) {
+				case *ast.IndexExpr:
+					fun = index.X
+				case *ast.IndexListExpr:
+					fun = index.X
+				}
+				switch fun := fun.(type) {
+				case *ast.Ident:
+					linkPackage("", fun.Name)
+				case *ast.SelectorExpr:
+					if typ := typeName(typeOf(fun.X)); typ != "" {
+						linkMethod(typ, fun.Sel.Name)
+					} else if x, ok := fun.X.(*ast.Ident); ok {
+						if typ, declared := types[x.Name]; !declared {
+							linkPackage(x.Name, fun.Sel.Name)
+						} else if typ != "" {
+							linkMethod(typ, fun.Sel.Name)
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	for _, fh := range history.Data {
+		sort.Slice(fh.Tests, func(i, j int) bool { return fh.Tests[i].ID < fh.Tests[j].ID })
+		sort.Slice(fh.Covers, func(i, j int) bool { return fh.Covers[i].ID < fh.Covers[j].ID })
+	}
+}
+
+// variableTypes maps names of variables and parameters declared in decl to names of their types, names declared
+// with different or unknown types map to empty string.
+func variableTypes(decl ast.Decl) map[string]string {
+	types := make(map[string]string)
+	declare := func(name *ast.Ident, typ ast.Expr) {
+		t, ok := types[name.Name]
+		if ok && t != typeName(typ) {
+			types[name.Name] = ""
+		} else if !ok {
+			types[name.Name] = typeName(typ)
+		}
+	}
+	ast.Inspect(decl, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Field:
+			for _, name := range n.Names {
+				declare(name, n.Type)
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				switch {
+				case n.Type != nil:
+					declare(name, n.Type)
+				case len(n.Values) == len(n.Names):
+					declare(name, typeOf(n.Values[i]))
+				default:
+					declare(name, nil)
+				}
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if name, ok := lhs.(*ast.Ident); ok && name.Name != "_" {
+					if len(n.Lhs) == len(n.Rhs) {
+						declare(name, typeOf(n.Rhs[i]))
+					} else {
+						declare(name, nil)
+					}
+				}
+			}
+		}
+		return true
+	})
+	return types
+}
+
+// typeOf returns type of value created by composite literal or new, it is nil for other expressions.
+func typeOf(x ast.Expr) ast.Expr {
+	switch x := x.(type) {
+	case *ast.CompositeLit:
+		return x.Type
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			return typeOf(x.X)
+		}
+	case *ast.ParenExpr:
+		return typeOf(x.X)
+	case *ast.CallExpr:
+		if fun, ok := x.Fun.(*ast.Ident); ok && fun.Name == "new" && len(x.Args) == 1 {
+			return x.Args[0]
+		}
+	}
+	return nil
+}
+
+// typeName returns name of named type, optionally qualified with package name, or pointer to it.
+func typeName(typ ast.Expr) string {
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.ParenExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.Name
+		case *ast.SelectorExpr:
+			if x, ok := t.X.(*ast.Ident); ok {
+				return x.Name + "." + t.Sel.Name
+			}
+			return ""
+		default:
+			return ""
+		}
+	}
+}
+
+// latest returns the newest element which is not a deletion.
+func (fh *FunctionHistory) latest() *HistoryElement {
+	elements := fh.SortedElements()
+	for i := len(elements) - 1; i >= 0; i-- {
+		if elements[i].Decl != nil {
+			return elements[i]
+		}
+	}
+	return nil
+}
+
+// TestsChanged returns tests of fh which were changed in commit of elem.
+func (fh *FunctionHistory) TestsChanged(elem *HistoryElement) (changed []*FunctionHistory) {
+	sha := elem.Commit.Hash.String()
+	for _, test := range fh.Tests {
+		if _, ok := test.Elements[sha]; ok {
+			changed = append(changed, test)
+		}
+	}
+	return changed
+}
+
+// UntestedChanges returns versions of fh introduced in commits which did not change any of its tests.
+func (fh *FunctionHistory) UntestedChanges() (untested []*HistoryElement) {
+	for _, elem := range fh.SortedElements() {
+		if elem.New && elem.Decl != nil && len(fh.TestsChanged(elem)) == 0 {
+			untested = append(untested, elem)
+		}
+	}
+	return untested
+}
@@ -0,0 +1,132 @@
+package objects
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/gittest"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestTestKindOf(t *testing.T) {
+	src := `package p
+
+func TestSum(t *testing.T) {}
+func Test(t *testing.T) {}
+func Testify(t *testing.T) {}
+func TestNoParam() {}
+func BenchmarkSum(b *testing.B) {}
+func BenchmarkWrongParam(t *testing.T) {}
+func FuzzSum(f *testing.F) {}
+func ExampleSum() {}
+func ExampleWithParam(x int) {}
+func (s S) TestMethod(t *testing.T) {}
+func helper(t *testing.T) {}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p_test.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]TestKind{
+		"TestSum":             TestKindTest,
+		"Test":                TestKindTest,
+		"Testify":             TestKindNone,
+		"TestNoParam":         TestKindNone,
+		"BenchmarkSum":        TestKindBenchmark,
+		"BenchmarkWrongParam": TestKindNone,
+		"FuzzSum":             TestKindFuzz,
+		"ExampleSum":          TestKindExample,
+		"ExampleWithParam":    TestKindNone,
+		"TestMethod":          TestKindNone,
+		"helper":              TestKindNone,
+	}
+	for _, decl := range f.Decls {
+		fn := decl.(*ast.FuncDecl)
+		if kind := TestKindOf(fn, "p_test.go"); kind != expected[fn.Name.Name] {
+			t.Errorf("%s: kind = %q, want %q", fn.Name.Name, kind, expected[fn.Name.Name])
+		}
+		if kind := TestKindOf(fn, "p.go"); kind != TestKindNone {
+			t.Errorf("%s: kind outside test file = %q", fn.Name.Name, kind)
+		}
+	}
+}
+
+func TestLinkTests(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "a@x", c1)
+	src := "func (T) M() {}\nfunc (U) M() {}\nfunc (*T) N() {}\n"
+	tests := `
+func TestF(t *testing.T) { F(0) }
+func TestM(t *testing.T) { x := T{}; x.M(); var y *T; y.N(); q.H() }
+func TestLiteral(t *testing.T) { (&U{}).M() }
+func TestUnknown(t *testing.T) { v := get(); v.M(); var w interface{ M() }; w.M() }
+`
+	analyze(t, history, c0, map[string]string{
+		"p/p.go":      "func F(x int) {}\nfunc G(x int) {}\n" + src,
+		"p/p_test.go": tests,
+		"q/q.go":      "func H() {}",
+	})
+	analyze(t, history, c1, map[string]string{
+		"p/p.go":      "func F(x int) { f() }\nfunc G(x int) { g() }\n" + src,
+		"p/p_test.go": strings.Replace(tests, "F(0)", "F(1)", 1),
+		"q/q.go":      "func H() {}",
+	})
+	analyze(t, history, c2, map[string]string{
+		"p/p.go":      "func F(x int) { f(x) }\nfunc G(x int) { g() }\n" + src,
+		"p/p_test.go": strings.Replace(tests, "F(0)", "F(1)", 1),
+		"q/q.go":      "func H() {}",
+	})
+	history.LinkTests()
+
+	covers := map[string][]string{
+		"p.TestF":       {"p.F"},
+		"p.TestM":       {"p.T.M", "p.T.N", "q.H"},
+		"p.TestLiteral": {"p.U.M"},
+		"p.TestUnknown": nil,
+		"p.T.M":         nil,
+	}
+	for id, want := range covers {
+		var got []string
+		for _, fh := range history.Data[id].Covers {
+			got = append(got, fh.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s covers %v, want %v", id, got, want)
+		}
+	}
+	tested := map[string][]string{
+		"p.F":   {"p.TestF"},
+		"p.G":   nil,
+		"p.T.M": {"p.TestM"},
+		"p.U.M": {"p.TestLiteral"},
+	}
+	for id, want := range tested {
+		var got []string
+		for _, fh := range history.Data[id].Tests {
+			got = append(got, fh.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s tests %v, want %v", id, got, want)
+		}
+	}
+
+	untested := map[string][]*object.Commit{
+		"p.F": {c2},
+		"p.G": {c0, c1},
+	}
+	for id, want := range untested {
+		var got []*object.Commit
+		for _, elem := range history.Data[id].UntestedChanges() {
+			got = append(got, elem.Commit)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s untested changes in %v, want %v", id, got, want)
+		}
+	}
+}
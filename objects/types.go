@@ -18,13 +18,43 @@ import (
 
 type History struct {
 	Data            map[string]*FunctionHistory
+	Types           map[string]*TypeHistory
+	Variables       map[string]*VariableHistory
 	CommitsAnalyzed int32
 	MaxChanged      int32
 	CountPerCommit  map[time.Time]int
+	// GeneratedPerCommit counts functions from generated files, they are not part of CountPerCommit
+	GeneratedPerCommit map[time.Time]int
+	Errors             []AnalysisError
+	// Heads are analyzed branches, the first one is the main analyzed revision
+	Heads []Head
+	// Commits are analyzed commits by sha
+	Commits map[string]*object.Commit
+	// Equivalence is how declarations were compared with their parents
+	Equivalence Equivalence
 
 	m sync.Mutex
 }
 
+// Head is analyzed revision with commit it resolved to.
+type Head struct {
+	Name string `json:"name"`
+	SHA  string `json:"sha"`
+}
+
+// AnalysisError describes file which could not be fully analyzed in given commit.
+type AnalysisError struct {
+	Commit string `json:"commit"`
+	File   string `json:"file"`
+	Error  string `json:"error"`
+}
+
+func (history *History) AddError(commit *object.Commit, file string, err error) {
+	history.m.Lock()
+	history.Errors = append(history.Errors, AnalysisError{Commit: commit.Hash.String(), File: file, Error: err.Error()})
+	history.m.Unlock()
+}
+
 func (history *History) Get(funcID string) *FunctionHistory {
 	history.m.Lock()
 	defer history.m.Unlock()
@@ -36,9 +66,48 @@ func (history *History) Get(funcID string) *FunctionHistory {
 	return funcHistory
 }
 
-func (history *History) Mark(sha time.Time, count int) {
+func (history *History) GetType(typeID string) *TypeHistory {
+	history.m.Lock()
+	defer history.m.Unlock()
+	typeHistory, ok := history.Types[typeID]
+	if !ok {
+		typeHistory = NewTypeHistory(typeID)
+		history.Types[typeID] = typeHistory
+	}
+	return typeHistory
+}
+
+func (history *History) GetVariable(varID string) *VariableHistory {
+	history.m.Lock()
+	defer history.m.Unlock()
+	varHistory, ok := history.Variables[varID]
+	if !ok {
+		varHistory = NewVariableHistory(varID)
+		history.Variables[varID] = varHistory
+	}
+	return varHistory
+}
+
+// Lookup finds existing history of function, type or variable with given id.
+func (history *History) Lookup(id string) *FunctionHistory {
+	history.m.Lock()
+	defer history.m.Unlock()
+	if fh, ok := history.Data[id]; ok {
+		return fh
+	}
+	if th, ok := history.Types[id]; ok {
+		return th.FunctionHistory
+	}
+	if vh, ok := history.Variables[id]; ok {
+		return vh.FunctionHistory
+	}
+	return nil
+}
+
+func (history *History) Mark(sha time.Time, count, generated int) {
 	history.m.Lock()
 	history.CountPerCommit[sha] = count
+	history.GeneratedPerCommit[sha] = generated
 	history.m.Unlock()
 }
 
@@ -48,17 +117,31 @@ func (history *History) CheckForDeleted(commit *object.Commit) {
 	for _, fh := range history.Data {
 		fh.Delete(commit)
 	}
+	for _, th := range history.Types {
+		th.Delete(commit)
+	}
+	for _, vh := range history.Variables {
+		vh.Delete(commit)
+	}
 }
 
 func NewHistory() *History {
 	return &History{
-		Data:           make(map[string]*FunctionHistory),
-		CountPerCommit: make(map[time.Time]int),
+		Data:               make(map[string]*FunctionHistory),
+		Types:              make(map[string]*TypeHistory),
+		Variables:          make(map[string]*VariableHistory),
+		CountPerCommit:     make(map[time.Time]int),
+		GeneratedPerCommit: make(map[time.Time]int),
+		Commits:            make(map[string]*object.Commit),
 	}
 }
 
-func (history *History) Stats() map[string]interface{} {
+// Stats summarizes function histories, histories of generated code are counted only when withGenerated is set.
+func (history *History) Stats(withGenerated bool) map[string]interface{} {
 	stats := make(map[string]interface{})
+	functions := 0
+	generated := 0
+	tests := 0
 	changes := 0
 	neverChanged := 0
 	mostChangedCount := 0
@@ -68,6 +151,16 @@ func (history *History) Stats() map[string]interface{} {
 	totalVersions := 0
 	var mostChanged string
 	for name, history := range history.Data {
+		if history.Test != TestKindNone {
+			tests++
+		}
+		if history.Generated {
+			generated++
+			if !withGenerated {
+				continue
+			}
+		}
+		functions++
 		versions := history.VersionsCount()
 		changes += versions - 1
 		totalLifetime += history.LifeTime
@@ -76,29 +169,35 @@ func (history *History) Stats() map[string]interface{} {
 		if versions == 1 {
 			neverChanged++
 		}
-		if history.Deleted {
+		if history.Deleted && history.Successor == nil {
 			removed++
 		}
-		if versions > mostChangedCount {
+		if versions > mostChangedCount || (versions == mostChangedCount && name < mostChanged) {
 			mostChanged = name
 			mostChangedCount = versions
 		}
 	}
 	stats["Analyzed commits"] = history.CommitsAnalyzed
-	if history.CommitsAnalyzed == 1 {
+	if history.CommitsAnalyzed <= 1 {
 		stats["Avg changes per commit"] = float64(changes)
 	} else {
 		stats["Avg changes per commit"] = float64(changes) / float64(history.CommitsAnalyzed)
 	}
-	stats["Avg changes per function"] = float64(changes) / float64(len(history.Data))
-	stats["Avg lifetime"] = float64(totalLifetime) / float64(len(history.Data))
-	stats["Avg edittime"] = float64(totalEditLifeTime) / float64(len(history.Data))
+	divisor := float64(util.IntMax(functions, 1))
+	stats["Avg changes per function"] = float64(changes) / divisor
+	stats["Avg lifetime"] = float64(totalLifetime) / divisor
+	stats["Avg edittime"] = float64(totalEditLifeTime) / divisor
 	stats["Max changes in commit"] = history.MaxChanged
 	stats["Total versions"] = totalVersions
 	stats["Never changed"] = neverChanged
-	stats["Functions"] = len(history.Data)
+	stats["Functions"] = functions
+	stats["Generated"] = generated
+	stats["Tests"] = tests
+	stats["Types"] = len(history.Types)
+	stats["Variables"] = len(history.Variables)
 	stats["Most changed"] = fmt.Sprintf("%v [%v]", mostChanged, mostChangedCount)
 	stats["Removed"] = removed
+	stats["Errors"] = len(history.Errors)
 	//stats["avgDepth"] = float64(diff.Depth) / float64(diff.CountSameCalls)
 	logrus.Infof("%v,%v,%v,%v,%v,%v,%v,%v",
 		stats["Analyzed commits"],
@@ -189,7 +288,7 @@ func ToStabilityGroup(stability float64) string {
 	return "active"
 }
 
-func (history *History) ChartsData() map[string]ChartData {
+func (history *History) ChartsData(withGenerated bool) map[string]ChartData {
 	charts := make(map[string]ChartData)
 
 	changesCount := make(map[int]int)
@@ -197,6 +296,9 @@ func (history *History) ChartsData() map[string]ChartData {
 	countPerDate := make(map[Date]int)
 	stabilityVersions := map[string]int{"stable": 0, "modified": 0, "active": 0}
 	for _, fHistory := range history.Data {
+		if fHistory.Generated && !withGenerated {
+			continue
+		}
 		changesCount[fHistory.VersionsCount()] += 1
 		stability := 1.0 - float64(fHistory.VersionsCount())/float64(fHistory.LifeTime)
 		stabilityVersions[ToStabilityGroup(stability)] += 1
@@ -241,6 +343,9 @@ func (history *History) ChartsData() map[string]ChartData {
 	}
 
 	for date, count := range history.CountPerCommit {
+		if withGenerated {
+			count += history.GeneratedPerCommit[date]
+		}
 		y, m, d := date.Date()
 		if count > countPerDate[Date{y, m, d}] {
 			countPerDate[Date{y, m, d}] = count
@@ -277,12 +382,25 @@ type FunctionHistory struct {
 	FirstAppearance time.Time
 	LastAppearance  time.Time
 	Deleted         bool
+	// Generated is set when the latest version comes from generated file
+	Generated bool
 
 	ID            string
 	Elements      map[string]*HistoryElement
 	First, Last   *HistoryElement
 	parentMapping map[string]map[string]bool
 	m             sync.Mutex
+
+	Origin, Successor               *FunctionHistory
+	OriginLineage, SuccessorLineage Lineage
+
+	// Test is kind of test function, Tests and Covers link tests with functions they call
+	Test          TestKind
+	Tests, Covers []*FunctionHistory
+
+	// Docs are versions of doc comment, tracked only when comments are analyzed
+	Docs       []*DocVersion
+	docMapping map[string]*DocVersion
 }
 
 func NewFunctionHistory(id string) *FunctionHistory {
@@ -293,7 +411,78 @@ func NewFunctionHistory(id string) *FunctionHistory {
 	}
 }
 
-func (fh *FunctionHistory) AddElement(decl *ast.FuncDecl, commit *object.Commit, body []byte, simple bool) bool {
+// Equivalence selects how declaration is compared with its parents to decide whether it changed.
+type Equivalence int
+
+const (
+	// EquivalenceAST compares syntax trees ignoring formatting and comments
+	EquivalenceAST Equivalence = iota
+	// EquivalenceText compares declaration text
+	EquivalenceText
+	// EquivalenceAlpha compares syntax trees ignoring consistent renames of local variables and parameters
+	EquivalenceAlpha
+)
+
+func (e Equivalence) same(parent *HistoryElement, decl ast.Decl, split *SplitName, text string) bool {
+	if parent.Split != nil && split != nil {
+		return e.sameSplit(parent, split, text, int(decl.Pos()))
+	}
+	switch e {
+	case EquivalenceText:
+		return diff.IsSameText(parent.Text, text)
+	case EquivalenceAlpha:
+		return diff.IsSameAlpha(parent.Decl, decl)
+	default:
+		return diff.IsSame(parent.Decl, decl)
+	}
+}
+
+// sameSplit compares split name of parent with split, offset is position of text start.
+func (e Equivalence) sameSplit(parent *HistoryElement, split *SplitName, text string, offset int) bool {
+	if parent.Split.Index != split.Index {
+		return false
+	}
+	switch e {
+	case EquivalenceText:
+		return diff.IsSameText(parent.Split.text(parent.Text, parent.Offset), split.text(text, offset))
+	case EquivalenceAlpha:
+		return diff.IsSameAlpha(parent.Split.Spec, split.Spec)
+	default:
+		return diff.IsSame(parent.Split.Spec, split.Spec)
+	}
+}
+
+// SplitName is a name of var or const spec declaring more names, or a constant repeating type and expression of
+// an earlier spec. Declaration of such name contains other names too, so only its own name, type and value in Spec
+// and its Index are compared.
+type SplitName struct {
+	Spec *ast.ValueSpec
+	// Index counts specs from the spec whose type and expression the constant repeats, iota depends on it
+	Index int
+}
+
+// text returns text of name, type and value of split, offset is position of text start in source.
+func (split *SplitName) text(text string, offset int) string {
+	parts := []ast.Node{split.Spec.Names[0]}
+	if split.Spec.Type != nil {
+		parts = append(parts, split.Spec.Type)
+	}
+	for _, value := range split.Spec.Values {
+		parts = append(parts, value)
+	}
+	pieces := make([]string, 0, len(parts))
+	for _, part := range parts {
+		pieces = append(pieces, text[int(part.Pos())-offset:int(part.End())-offset])
+	}
+	return strings.Join(pieces, " ")
+}
+
+func (fh *FunctionHistory) AddElement(decl ast.Decl, commit *object.Commit, file string, body []byte, equivalence Equivalence, generated bool) bool {
+	return fh.AddVariable(decl, nil, commit, file, body, equivalence, generated)
+}
+
+// AddVariable is AddElement of var or const declaration, split is set when the declaration contains other names too.
+func (fh *FunctionHistory) AddVariable(decl ast.Decl, split *SplitName, commit *object.Commit, file string, body []byte, equivalence Equivalence, generated bool) bool {
 	fh.m.Lock()
 	defer fh.m.Unlock()
 
@@ -319,8 +508,7 @@ func (fh *FunctionHistory) AddElement(decl *ast.FuncDecl, commit *object.Commit,
 				continue
 			}
 			parents[parentSHA] = parent
-			if (!simple && diff.IsSame(parent.Func, decl)) ||
-				(simple && diff.IsSameText(parent.Text, string(body[decl.Pos()-1:decl.End()-1]))) {
+			if equivalence.same(parent, decl, split, string(body[decl.Pos()-1:decl.End()-1])) {
 				anySame = true
 				parentMapping[parent.Commit.Hash.String()] = true
 			} else {
@@ -333,13 +521,20 @@ func (fh *FunctionHistory) AddElement(decl *ast.FuncDecl, commit *object.Commit,
 		return false
 	}
 	element := &HistoryElement{
-		Func:     decl,
-		Commit:   commit,
-		Parent:   parents,
-		Children: make(map[string]*HistoryElement),
-		Text:     string(body[decl.Pos()-1 : decl.End()-1]),
-		Offset:   int(decl.Pos()),
-		New:      !anySame,
+		Decl:      decl,
+		Split:     split,
+		Commit:    commit,
+		File:      file,
+		Parent:    parents,
+		Children:  make(map[string]*HistoryElement),
+		Text:      string(body[decl.Pos()-1 : decl.End()-1]),
+		Offset:    int(decl.Pos()),
+		New:       !anySame,
+		Generated: generated,
+	}
+	if parent := element.comparedParent(); parent != nil && element.New {
+		element.Category = element.Classify(parent)
+		element.Breaking = breakingChanges(parent, element)
 	}
 	fh.EditLifeTime = fh.LifeTime
 
@@ -381,22 +576,34 @@ func (fh *FunctionHistory) Delete(commit *object.Commit) {
 			if !ok {
 				continue
 			}
-			if parent.Func != nil {
+			if parent.Decl != nil {
 				anyNotDeleted = true
 			}
 			parents[parentSHA] = parent
 		}
 	}
 	if !anyNotDeleted {
+		// keep commits after deletion mapped to the deleted version
+		if len(parents) > 0 {
+			mapping := make(map[string]bool, len(parents))
+			for parentSHA := range parents {
+				mapping[parentSHA] = true
+			}
+			fh.parentMapping[sha] = mapping
+		}
 		return
 	}
 	element := &HistoryElement{
-		Func:     nil,
+		Decl:     nil,
 		Commit:   commit,
 		Parent:   parents,
 		Children: make(map[string]*HistoryElement),
 		New:      false,
 	}
+	for _, parent := range parents {
+		element.Generated = element.Generated || parent.Generated
+	}
+	element.Breaking = breakingChanges(element.comparedParent(), element)
 
 	for _, parent := range parents {
 		parent.Children[sha] = element
@@ -426,6 +633,49 @@ func (fh *FunctionHistory) PostProcess() {
 			}
 		}
 	}
+	if fh.Last != nil {
+		fh.Generated = fh.Last.Generated
+	}
+}
+
+// At returns version of function present in commit with given sha, it is nil when function was not present
+// in history of that commit and has nil Decl when function was deleted. Histories it was renamed or moved
+// from are searched when fh did not exist yet.
+func (fh *FunctionHistory) At(sha string) *HistoryElement {
+	if elem := fh.at(sha); elem != nil || fh.Origin == nil {
+		return elem
+	}
+	return fh.Origin.At(sha)
+}
+
+func (fh *FunctionHistory) at(sha string) *HistoryElement {
+	var shas []string
+	for elemSHA := range fh.parentMapping[sha] {
+		shas = append(shas, elemSHA)
+	}
+	sort.Strings(shas)
+	for _, elemSHA := range shas {
+		if elem, ok := fh.Elements[elemSHA]; ok {
+			return elem
+		}
+	}
+	return nil
+}
+
+// SortedElements returns elements ordered by commit time.
+func (fh *FunctionHistory) SortedElements() []*HistoryElement {
+	elements := make([]*HistoryElement, 0, len(fh.Elements))
+	for _, elem := range fh.Elements {
+		elements = append(elements, elem)
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		ti, tj := elements[i].Commit.Author.When, elements[j].Commit.Author.When
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return elements[i].Commit.Hash.String() < elements[j].Commit.Hash.String()
+	})
+	return elements
 }
 
 func (fh *FunctionHistory) VersionsCount() int {
@@ -438,21 +688,119 @@ func (fh *FunctionHistory) VersionsCount() int {
 	return versions
 }
 
+// ChangeCategories returns categories versions can be classified with, changes of formatting or comments only create
+// new versions only when declaration text is compared.
+func (history *History) ChangeCategories() []diff.Category {
+	if history.Equivalence == EquivalenceText {
+		return diff.Categories
+	}
+	var categories []diff.Category
+	for _, category := range diff.Categories {
+		if category != diff.CategoryFormatting && category != diff.CategoryComments {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// HasChange reports whether any version of fh changed declaration in one of given categories.
+func (fh *FunctionHistory) HasChange(category diff.Category) bool {
+	for _, elem := range fh.Elements {
+		if elem.Category&category != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 type HistoryElement struct {
 	Commit *object.Commit
-	Func   *ast.FuncDecl
+	Decl   ast.Decl
+	// Split is set for var or const name whose declaration contains other names too
+	Split  *SplitName
+	File   string
 	Text   string
 	Offset int
 	New    bool
+	// Generated is set when declaration comes from file with generated code header
+	Generated bool
+	// Category describes change from the first parent, it is empty for added and deleted versions
+	Category diff.Category
+	// Breaking lists incompatible changes of exported function signature from the first parent
+	Breaking []string
 
 	Parent   map[string]*HistoryElement
 	Children map[string]*HistoryElement
+	// Origin is version of another history this one was renamed or moved from, it is set only for the first version
+	Origin *HistoryElement
+}
+
+func (elem *HistoryElement) Func() *ast.FuncDecl {
+	f, _ := elem.Decl.(*ast.FuncDecl)
+	return f
+}
+
+// Classify returns category of change from compared element to elem.
+func (elem *HistoryElement) Classify(compared *HistoryElement) diff.Category {
+	return diff.ClassifyText(diff.Classify(compared.Decl, elem.Decl), compared.Text, elem.Text)
+}
+
+// Diff returns colorings of compared element and elem computed by engine, compared is usually one of parents and
+// may be nil. When elem is a deletion, the whole compared element is colored as removed. With alpha, consistently
+// renamed locals are not colored.
+func (elem *HistoryElement) Diff(compared *HistoryElement, engine diff.Engine, alpha bool) (left, right diff.Coloring) {
+	switch {
+	case compared == nil || compared.Decl == nil:
+		if elem.Decl != nil {
+			right = diff.Diff(nil, elem.Decl, diff.ModeNew)
+		}
+	case elem.Decl == nil:
+		left = diff.Diff(compared.Decl, nil, diff.ModeOld)
+	case engine == diff.EngineLCS:
+		left = diff.LCS(compared.Text, elem.Text, compared.Offset, diff.ModeOld)
+		right = diff.LCS(compared.Text, elem.Text, elem.Offset, diff.ModeNew)
+	case engine == diff.EngineTree && alpha:
+		left = diff.DiffTreeAlpha(compared.Decl, elem.Decl, diff.ModeOld)
+		right = diff.DiffTreeAlpha(elem.Decl, compared.Decl, diff.ModeNew)
+	case engine == diff.EngineTree:
+		left = diff.DiffTree(compared.Decl, elem.Decl, diff.ModeOld)
+		right = diff.DiffTree(elem.Decl, compared.Decl, diff.ModeNew)
+	case alpha:
+		left, right = diff.DiffAlphaPair(compared.Decl, elem.Decl)
+	default:
+		left, right = diff.DiffPair(compared.Decl, elem.Decl)
+	}
+	return
+}
+
+// EditScript returns edit script transforming compared element into elem, compared is usually one of parents and may
+// be nil.
+func (elem *HistoryElement) EditScript(compared *HistoryElement) *diff.Script {
+	var old ast.Node
+	if compared != nil && compared.Decl != nil {
+		old = compared.Decl
+	}
+	var new ast.Node
+	if elem.Decl != nil {
+		new = elem.Decl
+	}
+	return diff.EditScript(old, new)
+}
+
+type TypeHistory struct {
+	*FunctionHistory
+}
+
+func NewTypeHistory(id string) *TypeHistory {
+	return &TypeHistory{FunctionHistory: NewFunctionHistory(id)}
+}
+
+type VariableHistory struct {
+	*FunctionHistory
 }
 
-type Variable struct {
-	Name *ast.Ident
-	Type ast.Expr
-	Expr ast.Expr
+func NewVariableHistory(id string) *VariableHistory {
+	return &VariableHistory{FunctionHistory: NewFunctionHistory(id)}
 }
 
 func toStrings(m map[int]int) (string, string) {
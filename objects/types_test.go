@@ -0,0 +1,198 @@
+package objects
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/internal/gittest"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// testSource adds package clause to src of file with given name, package is named after directory of the file.
+func testSource(name, src string) string {
+	return "package " + path.Base(path.Dir(name)) + "\n" + src
+}
+
+// testID returns ID of function declared in file with given name, like collector does.
+func testID(name string, function *ast.FuncDecl) string {
+	pkg := path.Dir(name)
+	if function.Recv == nil {
+		return pkg + "." + function.Name.Name
+	}
+	recv := function.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	return pkg + "." + recv.(*ast.Ident).Name + "." + function.Name.Name
+}
+
+// analyze adds functions declared in files of commit to history with their docs like collector does, files map
+// names to sources without package clause.
+func analyze(t *testing.T, history *History, commit *object.Commit, files map[string]string) {
+	history.Commits[commit.Hash.String()] = commit
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		src := testSource(name, files[name])
+		f, err := parser.ParseFile(token.NewFileSet(), name, src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			function, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			id := testID(name, function)
+			history.Get(id).AddElement(function, commit, name, []byte(src), EquivalenceAST, false)
+			history.Get(id).AddDoc(DocText(function), commit)
+		}
+	}
+	history.CheckForDeleted(commit)
+	history.DetectRenames(commit)
+}
+
+func TestFunctionHistoryAt(t *testing.T) {
+	history := NewHistory()
+	c0 := gittest.Commit(0, "a@x")
+	c1 := gittest.Commit(1, "a@x", c0)
+	c2 := gittest.Commit(2, "a@x", c1)
+	c3 := gittest.Commit(3, "a@x", c2)
+	analyze(t, history, c0, map[string]string{"p/p.go": "func F() {}\nfunc G() {}"})
+	analyze(t, history, c1, map[string]string{"p/p.go": "func G() {}"})
+	analyze(t, history, c2, map[string]string{"p/p.go": "func G() { g() }"})
+	analyze(t, history, c3, map[string]string{"p/p.go": "func F() { f() }\nfunc G() { g() }"})
+
+	fh := history.Data["p.F"]
+	tests := []struct {
+		name    string
+		sha     string
+		commit  *object.Commit
+		deleted bool
+	}{
+		{"added", c0.Hash.String(), c0, false},
+		{"deleted", c1.Hash.String(), c1, true},
+		{"after deletion", c2.Hash.String(), c1, true},
+		{"added again", c3.Hash.String(), c3, false},
+		{"unknown", "unknown", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elem := fh.At(tt.sha)
+			if tt.commit == nil {
+				if elem != nil {
+					t.Errorf("At() = %v, want nil", elem.Commit.Hash)
+				}
+				return
+			}
+			if elem == nil {
+				t.Fatalf("At() = nil, want version of %s", tt.commit.Hash)
+			}
+			if elem.Commit != tt.commit || (elem.Decl == nil) != tt.deleted {
+				t.Errorf("At() = version of %s deleted %v, want %s deleted %v", elem.Commit.Hash, elem.Decl == nil,
+					tt.commit.Hash, tt.deleted)
+			}
+		})
+	}
+	if elem := fh.At(c3.Hash.String()); elem.Parent[c1.Hash.String()] == nil {
+		t.Errorf("version added again has parents %v, want deleted version", elem.Parent)
+	}
+}
+
+func TestChangeCategories(t *testing.T) {
+	tests := []struct {
+		equivalence Equivalence
+		formatting  bool
+	}{
+		{EquivalenceAST, false},
+		{EquivalenceAlpha, false},
+		{EquivalenceText, true},
+	}
+	for _, tt := range tests {
+		history := NewHistory()
+		history.Equivalence = tt.equivalence
+		var formatting, comments bool
+		for _, category := range history.ChangeCategories() {
+			formatting = formatting || category == diff.CategoryFormatting
+			comments = comments || category == diff.CategoryComments
+		}
+		if formatting != tt.formatting || comments != tt.formatting {
+			t.Errorf("equivalence %d: formatting listed %v, comments listed %v, want %v", tt.equivalence, formatting,
+				comments, tt.formatting)
+		}
+	}
+}
+
+func TestTypeHistory(t *testing.T) {
+	history := NewHistory()
+	versions := []string{
+		"type T struct {\n\tx int\n}",
+		"type T struct {\n\tx int\n}",
+		"type T struct {\n\tx int `json:\"x\"`\n}",
+		"type T struct {\n\tx int `json:\"x\"`\n\ty string\n}",
+	}
+	var commits []*object.Commit
+	for i, version := range versions {
+		commit := gittest.Commit(i, "a@x")
+		if i > 0 {
+			commit = gittest.Commit(i, "a@x", commits[i-1])
+		}
+		commits = append(commits, commit)
+		src := testSource("p/p.go", version)
+		f, err := parser.ParseFile(token.NewFileSet(), "p/p.go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history.GetType("p.T").AddElement(f.Decls[0], commit, "p/p.go", []byte(src), EquivalenceAST, false)
+		history.CheckForDeleted(commit)
+	}
+	th := history.Types["p.T"]
+	th.PostProcess()
+	if th.VersionsCount() != 3 {
+		t.Errorf("VersionsCount() = %d, want 3", th.VersionsCount())
+	}
+	if elem := th.At(commits[1].Hash.String()); elem == nil || elem.Commit != commits[0] {
+		t.Errorf("At(unchanged) = %v, want version of the first commit", elem)
+	}
+
+	tests := []struct {
+		name     string
+		commit   int
+		old, new []string
+	}{
+		{"tag added", 2, nil, []string{"`json:\"x\"`"}},
+		{"field added", 3, nil, []string{"y string"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elem, parent := th.Elements[commits[tt.commit].Hash.String()], th.At(commits[tt.commit-1].Hash.String())
+			if elem == nil || elem.Parent[parent.Commit.Hash.String()] != parent {
+				t.Fatalf("version of commit %d = %v, want child of %v", tt.commit, elem, parent)
+			}
+			left, right := elem.Diff(parent, diff.EngineAST, false)
+			if old := fragments(parent, left); !reflect.DeepEqual(old, tt.old) {
+				t.Errorf("old side colored %q, want %q", old, tt.old)
+			}
+			if new := fragments(elem, right); !reflect.DeepEqual(new, tt.new) {
+				t.Errorf("new side colored %q, want %q", new, tt.new)
+			}
+		})
+	}
+}
+
+// fragments returns parts of elem text covered by coloring.
+func fragments(elem *HistoryElement, coloring diff.Coloring) (result []string) {
+	for _, change := range coloring {
+		result = append(result, elem.Text[int(change.Pos)-elem.Offset:int(change.End)-elem.Offset+1])
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/objects"
+)
+
+// EngineBenchmark is time spent by engine on diffing all analyzed versions with their parents, Colored is average
+// share of text colored as changed and Moved is number of moved ranges.
+type EngineBenchmark struct {
+	Engine  string        `json:"engine"`
+	Pairs   int           `json:"pairs"`
+	Total   time.Duration `json:"total_ns"`
+	Average time.Duration `json:"average_ns"`
+	Slowest time.Duration `json:"slowest_ns"`
+	Colored float64       `json:"colored"`
+	Moved   int           `json:"moved"`
+}
+
+// NewBenchmark diffs every version of analyzed declarations with each of its parents by all engines.
+func NewBenchmark(history *objects.History, withGenerated bool) []EngineBenchmark {
+	type pair struct {
+		elem, parent *objects.HistoryElement
+	}
+	var pairs []pair
+	for _, fh := range history.Data {
+		if fh.Generated && !withGenerated {
+			continue
+		}
+		for _, elem := range fh.Elements {
+			if !elem.New || elem.Decl == nil {
+				continue
+			}
+			for _, parent := range elem.Parent {
+				if parent.Decl != nil {
+					pairs = append(pairs, pair{elem, parent})
+				}
+			}
+			if elem.Origin != nil {
+				pairs = append(pairs, pair{elem, elem.Origin})
+			}
+		}
+	}
+
+	benchmarks := make([]EngineBenchmark, 0, len(diff.Engines))
+	for _, engine := range diff.Engines {
+		b := EngineBenchmark{Engine: engine.String(), Pairs: len(pairs)}
+		var colored float64
+		for _, p := range pairs {
+			started := time.Now()
+			left, right := p.elem.Diff(p.parent, engine, false)
+			elapsed := time.Since(started)
+			b.Total += elapsed
+			if elapsed > b.Slowest {
+				b.Slowest = elapsed
+			}
+			size := 0
+			for _, change := range append(left, right...) {
+				if change.Color != diff.ColorSame {
+					size += int(change.End-change.Pos) + 1
+				}
+				if change.Color == diff.ColorMoved {
+					b.Moved++
+				}
+			}
+			if total := len(p.elem.Text) + len(p.parent.Text); total > 0 {
+				colored += float64(size) / float64(total)
+			}
+		}
+		if len(pairs) > 0 {
+			b.Average = b.Total / time.Duration(len(pairs))
+			b.Colored = colored / float64(len(pairs))
+		}
+		benchmarks = append(benchmarks, b)
+	}
+	return benchmarks
+}
+
+// WriteBenchmark writes engine benchmarks in given format.
+func WriteBenchmark(w io.Writer, benchmarks []EngineBenchmark, format string) error {
+	switch format {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "ENGINE\tPAIRS\tTOTAL\tAVERAGE\tSLOWEST\tCOLORED\tMOVED")
+		for _, b := range benchmarks {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%.1f%%\t%d\n", b.Engine, b.Pairs, b.Total, b.Average, b.Slowest,
+				100*b.Colored, b.Moved)
+		}
+		return tw.Flush()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"engine", "pairs", "total_ns", "average_ns", "slowest_ns", "colored", "moved"})
+		for _, b := range benchmarks {
+			cw.Write([]string{
+				b.Engine,
+				strconv.Itoa(b.Pairs),
+				strconv.FormatInt(int64(b.Total), 10),
+				strconv.FormatInt(int64(b.Average), 10),
+				strconv.FormatInt(int64(b.Slowest), 10),
+				strconv.FormatFloat(b.Colored, 'f', 4, 64),
+				strconv.Itoa(b.Moved),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(benchmarks)
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wookesh/gohist/objects"
+)
+
+// Breaking is report of incompatible changes of exported functions between two analyzed commits.
+type Breaking struct {
+	From    string                   `json:"from"`
+	To      string                   `json:"to"`
+	Changes []objects.BreakingChange `json:"changes"`
+}
+
+func NewBreaking(history *objects.History, from, to string) *Breaking {
+	return &Breaking{From: from, To: to, Changes: append([]objects.BreakingChange{}, history.BreakingChanges(from, to)...)}
+}
+
+func (b *Breaking) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(tw, "Breaking changes from %s to %s: %d\n\n", b.From, b.To, len(b.Changes))
+		fmt.Fprintln(tw, "ID\tCHANGES")
+		for _, change := range b.Changes {
+			fmt.Fprintf(tw, "%s\t%s\n", change.ID, strings.Join(change.Changes, "; "))
+		}
+		return tw.Flush()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "change"})
+		for _, change := range b.Changes {
+			for _, c := range change.Changes {
+				cw.Write([]string{change.ID, c})
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(b)
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
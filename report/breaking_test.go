@@ -0,0 +1,77 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/historytest"
+	"github.com/wookesh/gohist/objects"
+)
+
+func TestNewBreaking(t *testing.T) {
+	history, hashes := historytest.Linear(t,
+		"func F(x int) {}\nfunc G() {}\nfunc Old(a, b int) int { return a*b + a - b + a*a - b*b }",
+		"func F(x string) {}\nfunc New(a, b int) int { return a*b + a - b + a*a - b*b }",
+	)
+	tests := []struct {
+		name     string
+		from, to string
+		changes  []objects.BreakingChange
+	}{
+		{"changes", hashes[0], hashes[1], []objects.BreakingChange{
+			{ID: "p.F", Changes: []string{"parameter 1 retyped from int to string"}},
+			{ID: "p.G", Changes: []string{"removed"}},
+			{ID: "p.Old", Changes: []string{"renamed to p.New"}},
+		}},
+		{"none", hashes[1], hashes[1], []objects.BreakingChange{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaking(history, tt.from, tt.to)
+			if b.From != tt.from || b.To != tt.to || !reflect.DeepEqual(b.Changes, tt.changes) {
+				t.Errorf("NewBreaking() = %+v, want changes %+v", b, tt.changes)
+			}
+			var buf bytes.Buffer
+			if err := b.Write(&buf, FormatJSON); err != nil {
+				t.Fatal(err)
+			}
+			var decoded struct {
+				Changes []objects.BreakingChange `json:"changes"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Changes == nil {
+				t.Errorf("json changes = %v, %v, want list", decoded.Changes, err)
+			}
+		})
+	}
+}
+
+func TestBreakingWrite(t *testing.T) {
+	b := &Breaking{From: "a", To: "b", Changes: []objects.BreakingChange{
+		{ID: "p.F", Changes: []string{"removed"}},
+		{ID: "p.G", Changes: []string{"parameter 1 removed: int", "results changed from () to (error)"}},
+	}}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatText, "Breaking changes from a to b: 2\n\nID   CHANGES\np.F  removed\n" +
+			"p.G  parameter 1 removed: int; results changed from () to (error)\n"},
+		{FormatCSV, "id,change\np.F,removed\np.G,parameter 1 removed: int\np.G,results changed from () to (error)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := b.Write(&buf, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+	if err := b.Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("Write(xml) did not fail")
+	}
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/wookesh/gohist/objects"
+)
+
+// WriteHotspots writes ranked hotspots in given format.
+func WriteHotspots(w io.Writer, hotspots []objects.Hotspot, format string) error {
+	switch format {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "SCORE\tID\tPACKAGE\tVERSIONS\tLIFETIME\tAUTHORS\tCHURN")
+		for _, h := range hotspots {
+			fmt.Fprintf(tw, "%.1f\t%s\t%s\t%d\t%d\t%d\t%d\n", h.Score, h.ID, h.Package, h.Versions, h.LifeTime, h.Authors,
+				h.Churn)
+		}
+		return tw.Flush()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"score", "id", "package", "versions", "lifetime", "authors", "churn"})
+		for _, h := range hotspots {
+			cw.Write([]string{
+				strconv.FormatFloat(h.Score, 'f', 2, 64),
+				h.ID,
+				h.Package,
+				strconv.Itoa(h.Versions),
+				strconv.Itoa(h.LifeTime),
+				strconv.Itoa(h.Authors),
+				strconv.Itoa(h.Churn),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(hotspots)
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
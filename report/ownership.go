@@ -0,0 +1,66 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wookesh/gohist/objects"
+)
+
+// topAuthors prints up to three authors with the largest share.
+func topAuthors(authors []objects.AuthorShare) string {
+	var top []string
+	for i := 0; i < len(authors) && i < 3; i++ {
+		top = append(top, fmt.Sprintf("%s (%.0f%%)", authors[i].Email, 100*authors[i].Share))
+	}
+	return strings.Join(top, ", ")
+}
+
+// WriteOwnership writes ownership report in given format, csv contains only functions.
+func WriteOwnership(w io.Writer, r *objects.OwnershipReport, format string) error {
+	switch format {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "PACKAGE\tFUNCTIONS\tBUS FACTOR\tTOP AUTHORS")
+		for _, p := range r.Packages {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", p.Package, p.Functions, p.BusFactor, topAuthors(p.Authors))
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "ID\tBUS FACTOR\tLAST AUTHOR\tTOP AUTHORS")
+		for _, f := range r.Functions {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", f.ID, f.BusFactor, f.LastAuthor, topAuthors(f.Authors))
+		}
+		if len(r.Orphaned) > 0 {
+			fmt.Fprintln(tw)
+			fmt.Fprintln(tw, "LAST CHANGED BY AUTHORS WHO LEFT\tLAST AUTHOR")
+			for _, f := range r.Orphaned {
+				fmt.Fprintf(tw, "%s\t%s\n", f.ID, f.LastAuthor)
+			}
+		}
+		return tw.Flush()
+	case FormatCSV:
+		orphaned := make(map[string]bool, len(r.Orphaned))
+		for _, f := range r.Orphaned {
+			orphaned[f.ID] = true
+		}
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "package", "bus_factor", "last_author", "orphaned", "top_authors"})
+		for _, f := range r.Functions {
+			cw.Write([]string{f.ID, f.Package, strconv.Itoa(f.BusFactor), f.LastAuthor,
+				strconv.FormatBool(orphaned[f.ID]), topAuthors(f.Authors)})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
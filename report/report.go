@@ -0,0 +1,144 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/wookesh/gohist/objects"
+)
+
+const (
+	FormatText = "text"
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+type Entry struct {
+	Kind         string `json:"kind"`
+	ID           string `json:"id"`
+	Versions     int    `json:"versions"`
+	LifeTime     int    `json:"lifetime"`
+	EditLifeTime int    `json:"edit_lifetime"`
+	Deleted      bool   `json:"deleted"`
+	Generated    bool   `json:"generated"`
+}
+
+type Report struct {
+	Stats   map[string]interface{}  `json:"stats"`
+	Entries []Entry                 `json:"entries"`
+	Errors  []objects.AnalysisError `json:"errors"`
+}
+
+// New creates report of all histories, stats include generated code only when withGenerated is set.
+func New(history *objects.History, withGenerated bool) *Report {
+	r := &Report{Stats: history.Stats(withGenerated), Errors: append([]objects.AnalysisError{}, history.Errors...)}
+	sort.Slice(r.Errors, func(i, j int) bool {
+		if r.Errors[i].File != r.Errors[j].File {
+			return r.Errors[i].File < r.Errors[j].File
+		}
+		return r.Errors[i].Commit < r.Errors[j].Commit
+	})
+	add := func(kind string, fh *objects.FunctionHistory) {
+		r.Entries = append(r.Entries, Entry{
+			Kind:         kind,
+			ID:           fh.ID,
+			Versions:     fh.VersionsCount(),
+			LifeTime:     fh.LifeTime,
+			EditLifeTime: fh.EditLifeTime,
+			Deleted:      fh.Deleted,
+			Generated:    fh.Generated,
+		})
+	}
+	for _, fh := range history.Data {
+		add("function", fh)
+	}
+	for _, th := range history.Types {
+		add("type", th.FunctionHistory)
+	}
+	for _, vh := range history.Variables {
+		add("variable", vh.FunctionHistory)
+	}
+	sort.Slice(r.Entries, func(i, j int) bool {
+		if r.Entries[i].Kind != r.Entries[j].Kind {
+			return r.Entries[i].Kind < r.Entries[j].Kind
+		}
+		return r.Entries[i].ID < r.Entries[j].ID
+	})
+	return r
+}
+
+func (r *Report) statNames() []string {
+	names := make([]string, 0, len(r.Stats))
+	for name := range r.Stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText:
+		return r.writeText(w)
+	case FormatCSV:
+		return r.writeCSV(w)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, name := range r.statNames() {
+		fmt.Fprintf(tw, "%s:\t%v\n", name, r.Stats[name])
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "KIND\tID\tVERSIONS\tLIFETIME\tEDIT LIFETIME\tDELETED\tGENERATED")
+	for _, e := range r.Entries {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%v\t%v\n", e.Kind, e.ID, e.Versions, e.LifeTime, e.EditLifeTime, e.Deleted,
+			e.Generated)
+	}
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "COMMIT\tFILE\tERROR")
+		for _, e := range r.Errors {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Commit, e.File, e.Error)
+		}
+	}
+	return tw.Flush()
+}
+
+// writeCSV writes stats, entries and errors as one table, stats and errors use only id and value columns.
+func (r *Report) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"kind", "id", "versions", "lifetime", "edit_lifetime", "deleted", "generated", "value"})
+	for _, name := range r.statNames() {
+		cw.Write([]string{"stat", name, "", "", "", "", "", fmt.Sprint(r.Stats[name])})
+	}
+	for _, e := range r.Entries {
+		cw.Write([]string{
+			e.Kind,
+			e.ID,
+			strconv.Itoa(e.Versions),
+			strconv.Itoa(e.LifeTime),
+			strconv.Itoa(e.EditLifeTime),
+			strconv.FormatBool(e.Deleted),
+			strconv.FormatBool(e.Generated),
+			"",
+		})
+	}
+	for _, e := range r.Errors {
+		cw.Write([]string{"error", e.File, "", "", "", "", "", e.Commit + ": " + e.Error})
+	}
+	cw.Flush()
+	return cw.Error()
+}
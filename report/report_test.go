@@ -0,0 +1,92 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/wookesh/gohist/internal/historytest"
+	"github.com/wookesh/gohist/objects"
+)
+
+func TestNew(t *testing.T) {
+	history, hashes := historytest.Linear(t, "func G() {}\nfunc F() {}", "func F() { f() }")
+	history.Errors = []objects.AnalysisError{
+		{Commit: hashes[1], File: "b.go", Error: "b"},
+		{Commit: hashes[1], File: "a.go", Error: "a"},
+		{Commit: hashes[0], File: "b.go", Error: "c"},
+	}
+	r := New(history, false)
+	var ids []string
+	for _, e := range r.Entries {
+		ids = append(ids, e.Kind+" "+e.ID)
+	}
+	if want := []string{"function p.F", "function p.G"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("entries = %v, want %v", ids, want)
+	}
+	if !r.Entries[1].Deleted || r.Entries[0].Deleted {
+		t.Errorf("entries = %+v, want only p.G deleted", r.Entries)
+	}
+	var errors []string
+	for _, e := range r.Errors {
+		errors = append(errors, e.Error)
+	}
+	if want := []string{"a", "c", "b"}; !reflect.DeepEqual(errors, want) {
+		t.Errorf("errors = %v, want sorted by file and commit %v", errors, want)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	r := &Report{
+		Stats: map[string]interface{}{"Functions": 2, "Removed": 1},
+		Entries: []Entry{
+			{Kind: "function", ID: "p.F", Versions: 2, LifeTime: 3, EditLifeTime: 1},
+			{Kind: "function", ID: "p.G", Versions: 1, LifeTime: 1, Deleted: true},
+		},
+		Errors: []objects.AnalysisError{{Commit: "abc", File: "p.go", Error: "expected declaration"}},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatText, "Functions:  2\nRemoved:    1\n\n" +
+			"KIND      ID   VERSIONS  LIFETIME  EDIT LIFETIME  DELETED  GENERATED\n" +
+			"function  p.F  2         3         1              false    false\n" +
+			"function  p.G  1         1         0              true     false\n\n" +
+			"COMMIT  FILE  ERROR\n" +
+			"abc     p.go  expected declaration\n"},
+		{FormatCSV, "kind,id,versions,lifetime,edit_lifetime,deleted,generated,value\n" +
+			"stat,Functions,,,,,,2\nstat,Removed,,,,,,1\n" +
+			"function,p.F,2,3,1,false,false,\nfunction,p.G,1,1,0,true,false,\n" +
+			"error,p.go,,,,,,abc: expected declaration\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := r.Write(&buf, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Entries, r.Entries) || !reflect.DeepEqual(decoded.Errors, r.Errors) ||
+		decoded.Stats["Functions"] != 2.0 {
+		t.Errorf("json = %s, want report %+v", buf.String(), r)
+	}
+
+	if err := r.Write(&bytes.Buffer{}, "xml"); err == nil || err.Error() != "unknown report format: xml" {
+		t.Errorf("Write(xml) error = %v, want unknown format", err)
+	}
+}
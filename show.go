@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/objects"
+	"github.com/wookesh/gohist/terminal"
+)
+
+// show prints versions of function compared with their parents using engine, all changes are shown if commit is
+// empty. Versions are shown in two columns when sideBySide is set, alpha ignores consistent renames of locals.
+func show(w io.Writer, history *objects.History, id, commit string, engine diff.Engine, sideBySide, alpha bool) error {
+	fh := history.Lookup(id)
+	if fh == nil {
+		return fmt.Errorf("%s not found", id)
+	}
+
+	var elements []*objects.HistoryElement
+	if commit == "" {
+		for _, elem := range fh.SortedElements() {
+			if elem.New || elem.Decl == nil {
+				elements = append(elements, elem)
+			}
+		}
+	} else {
+		for sha, elem := range fh.Elements {
+			if strings.HasPrefix(sha, commit) {
+				elements = append(elements, elem)
+			}
+		}
+		if len(elements) == 0 {
+			return fmt.Errorf("%s has no version in commit %s", id, commit)
+		}
+		if len(elements) > 1 {
+			return fmt.Errorf("commit %s is ambiguous", commit)
+		}
+	}
+
+	render := terminal.Unified
+	if sideBySide {
+		render = terminal.SideBySide
+	}
+	bw := bufio.NewWriter(w)
+	for _, elem := range elements {
+		fmt.Fprintf(bw, "commit %s\nAuthor: %s <%s>\nDate:   %s\n\n", elem.Commit.Hash, elem.Commit.Author.Name,
+			elem.Commit.Author.Email, elem.Commit.Author.When.Format("Mon Jan 2 15:04:05 2006 -0700"))
+		for _, l := range strings.Split(strings.TrimSpace(elem.Commit.Message), "\n") {
+			fmt.Fprintln(bw, "    "+l)
+		}
+		fmt.Fprintln(bw)
+		if elem.Category != 0 {
+			fmt.Fprintf(bw, "Changes: %s\n\n", elem.Category)
+		}
+
+		if len(elem.Parent) == 0 {
+			render(bw, elem, elem.Origin, engine, alpha)
+			fmt.Fprintln(bw)
+		}
+		parents := make([]string, 0, len(elem.Parent))
+		for sha := range elem.Parent {
+			parents = append(parents, sha)
+		}
+		sort.Strings(parents)
+		for _, sha := range parents {
+			render(bw, elem, elem.Parent[sha], engine, alpha)
+			fmt.Fprintln(bw)
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,219 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/objects"
+)
+
+const (
+	tabWidth  = 4
+	separator = " | "
+	reset     = "\x1b[0m"
+)
+
+func ansi(c diff.Color) string {
+	switch c {
+	case diff.ColorNew:
+		return "\x1b[32m"
+	case diff.ColorRemoved:
+		return "\x1b[31m"
+	case diff.ColorSimilar:
+		return "\x1b[36m"
+	case diff.ColorMoved:
+		return "\x1b[35m"
+	default:
+		return ""
+	}
+}
+
+type line struct {
+	text   string
+	colors []diff.Color
+}
+
+// lines splits text into lines with color of every byte taken from coloring, offset is position of text beginning.
+func lines(text string, coloring diff.Coloring, offset int) []line {
+	if text == "" {
+		return nil
+	}
+	colors := make([]diff.Color, len(text))
+	for _, change := range coloring {
+		for p := int(change.Pos) - offset; p <= int(change.End)-offset; p++ {
+			if p >= 0 && p < len(colors) {
+				colors[p] = change.Color
+			}
+		}
+	}
+	var result []line
+	start := 0
+	for i := 0; i <= len(text); i++ {
+		if i == len(text) || text[i] == '\n' {
+			result = append(result, line{text: text[start:i], colors: colors[start:i]})
+			start = i + 1
+		}
+	}
+	return result
+}
+
+func (l line) width() int {
+	return utf8.RuneCountInString(strings.Replace(l.text, "\t", strings.Repeat(" ", tabWidth), -1))
+}
+
+// render returns line with ANSI colors, padded with spaces to given width.
+func (l line) render(width int) string {
+	var b strings.Builder
+	current := diff.ColorSame
+	for i := 0; i < len(l.text); i++ {
+		c := diff.ColorSame
+		if i < len(l.colors) {
+			c = l.colors[i]
+		}
+		if c != current {
+			if current != diff.ColorSame {
+				b.WriteString(reset)
+			}
+			b.WriteString(ansi(c))
+			current = c
+		}
+		if l.text[i] == '\t' {
+			b.WriteString(strings.Repeat(" ", tabWidth))
+		} else {
+			b.WriteByte(l.text[i])
+		}
+	}
+	if current != diff.ColorSame {
+		b.WriteString(reset)
+	}
+	if pad := width - l.width(); pad > 0 {
+		b.WriteString(strings.Repeat(" ", pad))
+	}
+	return b.String()
+}
+
+type opKind int
+
+const (
+	opSame opKind = iota
+	opRemoved
+	opAdded
+)
+
+type op struct {
+	kind     opKind
+	old, new int
+}
+
+// align matches lines of both versions by text using longest common subsequence.
+func align(old, new []line) []op {
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i].text == new[j].text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && j < len(new) && old[i].text == new[j].text:
+			ops = append(ops, op{opSame, i, j})
+			i++
+			j++
+		case j == len(new) || (i < len(old) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{opRemoved, i, -1})
+			i++
+		default:
+			ops = append(ops, op{opAdded, -1, j})
+			j++
+		}
+	}
+	return ops
+}
+
+func prepare(elem, compared *objects.HistoryElement, engine diff.Engine, alpha bool) (old, new []line) {
+	left, right := elem.Diff(compared, engine, alpha)
+	if compared != nil {
+		old = lines(compared.Text, left, compared.Offset)
+	}
+	return old, lines(elem.Text, right, elem.Offset)
+}
+
+func describe(elem *objects.HistoryElement) string {
+	if elem == nil {
+		return "/dev/null"
+	}
+	sha := elem.Commit.Hash.String()[:7]
+	if elem.Decl == nil {
+		return sha + " (deleted)"
+	}
+	return sha + " " + elem.File
+}
+
+// Unified writes elem compared with compared (usually its parent, may be nil) one under another.
+func Unified(w io.Writer, elem, compared *objects.HistoryElement, engine diff.Engine, alpha bool) {
+	old, new := prepare(elem, compared, engine, alpha)
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", describe(compared), describe(elem))
+	for _, o := range align(old, new) {
+		switch o.kind {
+		case opSame:
+			fmt.Fprintln(w, " "+new[o.new].render(0))
+		case opRemoved:
+			fmt.Fprintln(w, ansi(diff.ColorRemoved)+"-"+reset+old[o.old].render(0))
+		case opAdded:
+			fmt.Fprintln(w, ansi(diff.ColorNew)+"+"+reset+new[o.new].render(0))
+		}
+	}
+}
+
+// SideBySide writes elem compared with compared (usually its parent, may be nil) in two columns.
+func SideBySide(w io.Writer, elem, compared *objects.HistoryElement, engine diff.Engine, alpha bool) {
+	old, new := prepare(elem, compared, engine, alpha)
+	width := len(describe(compared))
+	for _, l := range old {
+		if l.width() > width {
+			width = l.width()
+		}
+	}
+	fmt.Fprintln(w, line{text: describe(compared)}.render(width)+separator+describe(elem))
+
+	var removed, added []line
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			var left, right line
+			if i < len(removed) {
+				left = removed[i]
+			}
+			if i < len(added) {
+				right = added[i]
+			}
+			fmt.Fprintln(w, left.render(width)+separator+right.render(0))
+		}
+		removed, added = nil, nil
+	}
+	for _, o := range align(old, new) {
+		switch o.kind {
+		case opSame:
+			flush()
+			fmt.Fprintln(w, old[o.old].render(width)+separator+new[o.new].render(0))
+		case opRemoved:
+			removed = append(removed, old[o.old])
+		case opAdded:
+			added = append(added, new[o.new])
+		}
+	}
+	flush()
+}
@@ -0,0 +1,142 @@
+package terminal
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/internal/gittest"
+	"github.com/wookesh/gohist/objects"
+)
+
+var escapes = regexp.MustCompile("\x1b\\[[0-9]+m")
+
+// element returns version of function declared in src committed in commit i.
+func element(t *testing.T, i int, src string) *objects.HistoryElement {
+	src = "package p\n" + src
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decl := f.Decls[0]
+	return &objects.HistoryElement{
+		Commit: gittest.Commit(i, "a@x"),
+		Decl:   decl,
+		File:   "p.go",
+		Text:   src[decl.Pos()-1 : decl.End()-1],
+		Offset: int(decl.Pos()),
+	}
+}
+
+func TestLines(t *testing.T) {
+	coloring := diff.Coloring{
+		{Pos: 10, End: 11, Color: diff.ColorNew},
+		{Pos: 16, End: 30, Color: diff.ColorRemoved},
+	}
+	result := lines("ab\ncd\n\tef", coloring, 10)
+	var texts []string
+	for _, l := range result {
+		texts = append(texts, l.text)
+	}
+	if !reflect.DeepEqual(texts, []string{"ab", "cd", "\tef"}) {
+		t.Fatalf("lines() = %q, want 3 lines", texts)
+	}
+	want := [][]diff.Color{
+		{diff.ColorNew, diff.ColorNew},
+		{diff.ColorSame, diff.ColorSame},
+		{diff.ColorRemoved, diff.ColorRemoved, diff.ColorRemoved},
+	}
+	for i, l := range result {
+		if !reflect.DeepEqual(l.colors, want[i]) {
+			t.Errorf("line %d colors = %v, want %v", i, l.colors, want[i])
+		}
+	}
+	if result[2].width() != tabWidth+2 {
+		t.Errorf("width() = %d, want %d", result[2].width(), tabWidth+2)
+	}
+	if rendered := result[0].render(4); rendered != ansi(diff.ColorNew)+"ab"+reset+"  " {
+		t.Errorf("render() = %q", rendered)
+	}
+	if lines("", coloring, 0) != nil {
+		t.Error("lines() of empty text is not empty")
+	}
+}
+
+func TestAlign(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		ops      string
+	}{
+		{"same", "a\nb", "a\nb", "=="},
+		{"added", "a\nc", "a\nb\nc", "=+="},
+		{"removed", "a\nb\nc", "a\nc", "=-="},
+		{"replaced", "a\nb\nc", "a\nx\nc", "=-+="},
+		{"from empty", "", "a\nb", "++"},
+		{"to empty", "a", "", "-"},
+	}
+	symbols := map[opKind]string{opSame: "=", opRemoved: "-", opAdded: "+"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old, new := lines(tt.old, nil, 0), lines(tt.new, nil, 0)
+			var ops string
+			for _, o := range align(old, new) {
+				ops += symbols[o.kind]
+				if o.kind == opSame && old[o.old].text != new[o.new].text {
+					t.Errorf("aligned %q with %q", old[o.old].text, new[o.new].text)
+				}
+			}
+			if ops != tt.ops {
+				t.Errorf("align() = %s, want %s", ops, tt.ops)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	old := element(t, 0, "func F() {\n\ta()\n\tb()\n}")
+	elem := element(t, 1, "func F() {\n\ta()\n\tc()\n}")
+	tests := []struct {
+		name     string
+		compared *objects.HistoryElement
+		want     string
+	}{
+		{"changed", old, "--- 0000000 p.go\n+++ 0000000 p.go\n func F() {\n     a()\n-    b()\n+    c()\n }\n"},
+		{"added", nil, "--- /dev/null\n+++ 0000000 p.go\n+func F() {\n+    a()\n+    c()\n+}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			Unified(&buf, elem, tt.compared, diff.EngineAST, false)
+			if got := escapes.ReplaceAllString(buf.String(), ""); got != tt.want {
+				t.Errorf("Unified() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	old := element(t, 0, "func F() {\n\ta()\n\tb()\n\td()\n}")
+	elem := element(t, 1, "func F() {\n\ta()\n\tc()\n}")
+	var buf bytes.Buffer
+	SideBySide(&buf, elem, old, diff.EngineAST, false)
+	want := []string{
+		"0000000 p.go | 0000000 p.go",
+		"func F() {   | func F() {",
+		"    a()      |     a()",
+		"    b()      |     c()",
+		"    d()      | ",
+		"}            | }",
+	}
+	if got := strings.Split(strings.TrimSuffix(escapes.ReplaceAllString(buf.String(), ""), "\n"), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("SideBySide() = %q, want %q", got, want)
+	}
+	if !strings.Contains(buf.String(), ansi(diff.ColorRemoved)+"d()") {
+		t.Errorf("SideBySide() = %q, removed statement is not colored", buf.String())
+	}
+}
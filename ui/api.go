@@ -0,0 +1,529 @@
+package ui
+
+import (
+	"fmt"
+	"go/ast"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/wookesh/gohist/diff"
+	"github.com/wookesh/gohist/objects"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+type APIError struct {
+	Error string `json:"error"`
+}
+
+type APIHistory struct {
+	ID               string   `json:"id"`
+	Versions         int      `json:"versions"`
+	LifeTime         int      `json:"lifetime"`
+	EditLifeTime     int      `json:"edit_lifetime"`
+	Deleted          bool     `json:"deleted"`
+	Generated        bool     `json:"generated"`
+	First            string   `json:"first"`
+	Last             string   `json:"last"`
+	Origin           string   `json:"origin,omitempty"`
+	OriginLineage    string   `json:"origin_lineage,omitempty"`
+	Successor        string   `json:"successor,omitempty"`
+	SuccessorLineage string   `json:"successor_lineage,omitempty"`
+	Test             string   `json:"test,omitempty"`
+	Tests            []string `json:"tests,omitempty"`
+	Covers           []string `json:"covers,omitempty"`
+	UntestedChanges  []string `json:"untested_changes,omitempty"`
+}
+
+type APICommit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+}
+
+type APIElement struct {
+	Commit   APICommit `json:"commit"`
+	File     string    `json:"file,omitempty"`
+	New      bool      `json:"new"`
+	Deleted  bool      `json:"deleted"`
+	Parents  []string  `json:"parents"`
+	Children []string  `json:"children"`
+	Origin   string    `json:"origin,omitempty"`
+	Changes  []string  `json:"changes,omitempty"`
+	Breaking []string  `json:"breaking,omitempty"`
+}
+
+// APIColorChange describes colored range of element text, Pos and End are inclusive offsets in Text. Moved is offset
+// of moved statement in text of the other side.
+type APIColorChange struct {
+	Color string `json:"color"`
+	Pos   int    `json:"pos"`
+	End   int    `json:"end"`
+	Moved *int   `json:"moved,omitempty"`
+}
+
+type APISide struct {
+	Element  APIElement       `json:"element"`
+	Text     string           `json:"text"`
+	Coloring []APIColorChange `json:"coloring"`
+}
+
+type APIDiff struct {
+	ID    string   `json:"id"`
+	Left  *APISide `json:"left,omitempty"`
+	Right APISide  `json:"right"`
+}
+
+func newAPIHistory(f *objects.FunctionHistory) APIHistory {
+	h := APIHistory{
+		ID:           f.ID,
+		Versions:     f.VersionsCount(),
+		LifeTime:     f.LifeTime,
+		EditLifeTime: f.EditLifeTime,
+		Deleted:      f.Deleted,
+		Generated:    f.Generated,
+		First:        f.First.Commit.Hash.String(),
+		Last:         f.Last.Commit.Hash.String(),
+	}
+	if f.Origin != nil {
+		h.Origin = f.Origin.ID
+		h.OriginLineage = f.OriginLineage.String()
+	}
+	if f.Successor != nil {
+		h.Successor = f.Successor.ID
+		h.SuccessorLineage = f.SuccessorLineage.String()
+	}
+	h.Test = f.Test.String()
+	for _, test := range f.Tests {
+		h.Tests = append(h.Tests, test.ID)
+	}
+	for _, covered := range f.Covers {
+		h.Covers = append(h.Covers, covered.ID)
+	}
+	if len(f.Tests) > 0 {
+		for _, elem := range f.UntestedChanges() {
+			h.UntestedChanges = append(h.UntestedChanges, elem.Commit.Hash.String())
+		}
+	}
+	return h
+}
+
+func newAPICommit(commit *object.Commit) APICommit {
+	return APICommit{
+		Hash:    commit.Hash.String(),
+		Author:  commit.Author.Name,
+		Email:   commit.Author.Email,
+		Date:    commit.Author.When,
+		Message: commit.Message,
+	}
+}
+
+func newAPIElement(elem *objects.HistoryElement) APIElement {
+	e := APIElement{
+		Commit:   newAPICommit(elem.Commit),
+		File:     elem.File,
+		New:      elem.New,
+		Deleted:  elem.Decl == nil,
+		Parents:  make([]string, 0, len(elem.Parent)),
+		Children: make([]string, 0, len(elem.Children)),
+		Changes:  elem.Category.Names(),
+		Breaking: elem.Breaking,
+	}
+	for sha := range elem.Parent {
+		e.Parents = append(e.Parents, sha)
+	}
+	if elem.Origin != nil {
+		e.Origin = elem.Origin.Commit.Hash.String()
+	}
+	for sha := range elem.Children {
+		e.Children = append(e.Children, sha)
+	}
+	sort.Strings(e.Parents)
+	sort.Strings(e.Children)
+	return e
+}
+
+func newAPISide(elem, other *objects.HistoryElement, coloring diff.Coloring) APISide {
+	side := APISide{Element: newAPIElement(elem), Text: elem.Text, Coloring: make([]APIColorChange, 0, len(coloring))}
+	for _, change := range coloring {
+		apiChange := APIColorChange{
+			Color: change.Color.String(),
+			Pos:   int(change.Pos) - elem.Offset,
+			End:   int(change.End) - elem.Offset,
+		}
+		if change.Move != nil && other != nil {
+			moved := int(change.Move.Other) - other.Offset
+			apiChange.Moved = &moved
+		}
+		side.Coloring = append(side.Coloring, apiChange)
+	}
+	return side
+}
+
+func (h *handler) apiHistory(c echo.Context, kind string) (*objects.FunctionHistory, error) {
+	name, err := url.QueryUnescape(c.Param("name"))
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, APIError{err.Error()})
+	}
+	f := h.lookup(kind, name)
+	if f == nil {
+		return nil, c.JSON(http.StatusNotFound, APIError{"not found: " + name})
+	}
+	return f, nil
+}
+
+func (h *handler) APIList(kind string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		withGenerated, err := strconv.ParseBool(c.QueryParam("generated"))
+		if err != nil {
+			withGenerated = false
+		}
+		var category diff.Category
+		if change := c.QueryParam("change"); change != "" {
+			if category, err = diff.ParseCategory(change); err != nil {
+				return c.JSON(http.StatusBadRequest, APIError{err.Error()})
+			}
+		}
+		histories := h.histories(kind)
+		result := make([]APIHistory, 0, len(histories))
+		for _, f := range histories {
+			if f.Generated && !withGenerated {
+				continue
+			}
+			if category != 0 && !f.HasChange(category) {
+				continue
+			}
+			result = append(result, newAPIHistory(f))
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+func (h *handler) APIGet(kind string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		f, err := h.apiHistory(c, kind)
+		if f == nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, newAPIHistory(f))
+	}
+}
+
+func (h *handler) APIVersions(kind string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		f, err := h.apiHistory(c, kind)
+		if f == nil {
+			return err
+		}
+		result := make([]APIElement, 0, len(f.Elements))
+		for _, elem := range f.SortedElements() {
+			result = append(result, newAPIElement(elem))
+		}
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// apiCompared returns element selected by from and to revisions or by pos and cmp with element it is compared with.
+func (h *handler) apiCompared(c echo.Context, f *objects.FunctionHistory) (element, compared *objects.HistoryElement,
+	err error) {
+	if from, to := c.QueryParam("from"), c.QueryParam("to"); from != "" || to != "" {
+		compared, element, err = h.compareRevisions(f, from, h.orMainHead(to))
+		return element, compared, err
+	}
+	pos, cmp := selectElements(f, c.QueryParam("pos"), c.QueryParam("cmp"))
+	element = f.Elements[pos]
+	return element, element.Compared(cmp), nil
+}
+
+func (h *handler) APIDiff(kind string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		f, err := h.apiHistory(c, kind)
+		if f == nil {
+			return err
+		}
+		element, compared, err := h.apiCompared(c, f)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, APIError{err.Error()})
+		}
+		engine, err := diffEngine(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, APIError{err.Error()})
+		}
+		left, right := element.Diff(compared, engine, c.QueryParam("alpha") == "yes")
+		result := APIDiff{ID: f.ID, Right: newAPISide(element, compared, right)}
+		if compared != nil {
+			side := newAPISide(compared, element, left)
+			result.Left = &side
+		}
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// APINode is node of element, Pos and End are inclusive offsets in text of the element.
+type APINode struct {
+	Node string `json:"node"`
+	Pos  int    `json:"pos"`
+	End  int    `json:"end"`
+	Text string `json:"text"`
+}
+
+func newAPINode(node ast.Node, elem *objects.HistoryElement) *APINode {
+	result := &APINode{
+		Node: strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast."),
+		Pos:  int(node.Pos()) - elem.Offset,
+		End:  int(node.End()) - 1 - elem.Offset,
+	}
+	if result.Pos >= 0 && result.End < len(elem.Text) {
+		result.Text = elem.Text[result.Pos : result.End+1]
+	}
+	return result
+}
+
+type APIMatch struct {
+	Old *APINode `json:"old"`
+	New *APINode `json:"new"`
+}
+
+// APIAction is edit script action, Old is missing for inserts and New for deletes.
+type APIAction struct {
+	Kind string   `json:"kind"`
+	Old  *APINode `json:"old,omitempty"`
+	New  *APINode `json:"new,omitempty"`
+}
+
+// APIScript is edit script transforming Old element into New one, Old is missing when New has no parent.
+type APIScript struct {
+	ID      string      `json:"id"`
+	Old     *APIElement `json:"old,omitempty"`
+	New     APIElement  `json:"new"`
+	Matches []APIMatch  `json:"matches"`
+	Actions []APIAction `json:"actions"`
+}
+
+func (h *handler) APIScript(kind string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		f, err := h.apiHistory(c, kind)
+		if f == nil {
+			return err
+		}
+		element, compared, err := h.apiCompared(c, f)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, APIError{err.Error()})
+		}
+		script := element.EditScript(compared)
+		result := APIScript{ID: f.ID, New: newAPIElement(element), Matches: []APIMatch{}, Actions: []APIAction{}}
+		if compared != nil {
+			old := newAPIElement(compared)
+			result.Old = &old
+		}
+		for _, match := range script.Matches {
+			result.Matches = append(result.Matches, APIMatch{
+				Old: newAPINode(match.Old, compared),
+				New: newAPINode(match.New, element),
+			})
+		}
+		for _, action := range script.Actions {
+			apiAction := APIAction{Kind: action.Kind.String()}
+			if action.Old != nil {
+				apiAction.Old = newAPINode(action.Old, compared)
+			}
+			if action.New != nil {
+				apiAction.New = newAPINode(action.New, element)
+			}
+			result.Actions = append(result.Actions, apiAction)
+		}
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+type APIBlameEntry struct {
+	Node   string `json:"node"`
+	Pos    int    `json:"pos"`
+	End    int    `json:"end"`
+	Commit string `json:"commit"`
+}
+
+// APIBlame holds commits which introduced blamed element nodes, Lines has commit of every text line, empty for blank lines.
+type APIBlame struct {
+	ID      string          `json:"id"`
+	Element APIElement      `json:"element"`
+	Entries []APIBlameEntry `json:"entries"`
+	Lines   []string        `json:"lines"`
+}
+
+func (h *handler) APIBlame(kind string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		f, err := h.apiHistory(c, kind)
+		if f == nil {
+			return err
+		}
+		pos, _ := selectElements(f, c.QueryParam("pos"), "")
+		if c.QueryParam("pos") == "" {
+			pos = f.Last.Commit.Hash.String()
+		}
+		element := f.Elements[pos]
+		blame := element.Blame()
+		result := APIBlame{ID: f.ID, Element: newAPIElement(element), Entries: make([]APIBlameEntry, 0, len(blame.Entries))}
+		for _, entry := range blame.Entries {
+			result.Entries = append(result.Entries, APIBlameEntry{
+				Node:   strings.TrimPrefix(fmt.Sprintf("%T", entry.Node), "*ast."),
+				Pos:    int(entry.Node.Pos()) - element.Offset,
+				End:    int(entry.Node.End()) - 1 - element.Offset,
+				Commit: entry.Element.Commit.Hash.String(),
+			})
+		}
+		for _, elem := range blame.Lines() {
+			if elem == nil {
+				result.Lines = append(result.Lines, "")
+			} else {
+				result.Lines = append(result.Lines, elem.Commit.Hash.String())
+			}
+		}
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// APIDoc is version of doc comment, Parent is commit of version it was changed from.
+type APIDoc struct {
+	Commit APICommit `json:"commit"`
+	Text   string    `json:"text"`
+	Parent string    `json:"parent,omitempty"`
+}
+
+func (h *handler) APIDocs(kind string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		f, err := h.apiHistory(c, kind)
+		if f == nil {
+			return err
+		}
+		result := make([]APIDoc, 0, len(f.Docs))
+		for _, version := range f.SortedDocs() {
+			doc := APIDoc{Commit: newAPICommit(version.Commit), Text: version.Text}
+			if version.Parent != nil {
+				doc.Parent = version.Parent.Commit.Hash.String()
+			}
+			result = append(result, doc)
+		}
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// APIBranch is version of history on analyzed branch, Element is missing when branch never contained it.
+type APIBranch struct {
+	objects.Head
+	Element *APIElement `json:"element,omitempty"`
+}
+
+func (h *handler) APIBranches(kind string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		f, err := h.apiHistory(c, kind)
+		if f == nil {
+			return err
+		}
+		result := make([]APIBranch, 0, len(h.history.Heads))
+		for _, head := range h.history.Heads {
+			branch := APIBranch{Head: head}
+			if elem := f.At(head.SHA); elem != nil {
+				e := newAPIElement(elem)
+				branch.Element = &e
+			}
+			result = append(result, branch)
+		}
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// APIChange is declaration changed in commit, Left is missing for added and Right for removed declarations.
+type APIChange struct {
+	Kind   string   `json:"kind"`
+	ID     string   `json:"id"`
+	Change string   `json:"change"`
+	Origin string   `json:"origin,omitempty"`
+	Left   *APISide `json:"left,omitempty"`
+	Right  *APISide `json:"right,omitempty"`
+}
+
+type APICommitChanges struct {
+	Commit   APICommit   `json:"commit"`
+	Parents  []string    `json:"parents"`
+	Children []string    `json:"children"`
+	Changes  []APIChange `json:"changes"`
+}
+
+func (h *handler) APICommitChanges(c echo.Context) error {
+	commit := h.commit(c.Param("sha"))
+	if commit == nil {
+		return c.JSON(http.StatusNotFound, APIError{"commit not analyzed: " + c.Param("sha")})
+	}
+	engine, err := diffEngine(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, APIError{err.Error()})
+	}
+	result := APICommitChanges{Commit: newAPICommit(commit), Changes: []APIChange{}}
+	result.Parents, result.Children = h.commitNeighbours(commit)
+	for _, change := range h.history.CommitChanges(commit.Hash.String()) {
+		left, right := change.Element.Diff(change.Parent, engine, c.QueryParam("alpha") == "yes")
+		apiChange := APIChange{Kind: change.Kind, ID: change.History.ID, Change: change.Type.String()}
+		if change.Parent != nil {
+			side := newAPISide(change.Parent, change.Element, left)
+			apiChange.Left = &side
+			if owner := historyOf(change.History, change.Parent); owner != change.History {
+				apiChange.Origin = owner.ID
+			}
+		}
+		if change.Element.Decl != nil {
+			side := newAPISide(change.Element, change.Parent, right)
+			apiChange.Right = &side
+		}
+		result.Changes = append(result.Changes, apiChange)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+type APIBreaking struct {
+	From    string                   `json:"from"`
+	To      string                   `json:"to"`
+	Changes []objects.BreakingChange `json:"changes"`
+}
+
+func (h *handler) APIBreaking(c echo.Context) error {
+	from, to := h.commit(c.QueryParam("from")), h.commit(h.orMainHead(c.QueryParam("to")))
+	if from == nil || to == nil {
+		return c.JSON(http.StatusBadRequest, APIError{"from and to have to be analyzed revisions"})
+	}
+	result := APIBreaking{From: from.Hash.String(), To: to.Hash.String(), Changes: []objects.BreakingChange{}}
+	result.Changes = append(result.Changes, h.history.BreakingChanges(result.From, result.To)...)
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *handler) APIHotspots(c echo.Context) error {
+	withGenerated, err := strconv.ParseBool(c.QueryParam("generated"))
+	if err != nil {
+		withGenerated = false
+	}
+	sortBy := c.QueryParam("sort")
+	if sortBy == "" {
+		sortBy = "score"
+	}
+	hotspots := h.history.Hotspots(c.QueryParam("package"), withGenerated)
+	if err := objects.SortHotspots(hotspots, sortBy); err != nil {
+		return c.JSON(http.StatusBadRequest, APIError{err.Error()})
+	}
+	return c.JSON(http.StatusOK, hotspots)
+}
+
+func (h *handler) APIOwnership(c echo.Context) error {
+	withGenerated, err := strconv.ParseBool(c.QueryParam("generated"))
+	if err != nil {
+		withGenerated = false
+	}
+	left := strings.Split(c.QueryParam("left"), ",")
+	return c.JSON(http.StatusOK, h.history.Ownership(c.QueryParam("package"), left, withGenerated))
+}
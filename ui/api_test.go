@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/wookesh/gohist/internal/historytest"
+	"github.com/wookesh/gohist/objects"
+)
+
+// testHandler serves linear history of package p analyzed by historytest.Linear, revisions are resolved from full
+// hashes and HEAD pointing to the last commit. It returns commit hashes from the oldest.
+func testHandler(t *testing.T, versions ...string) (*handler, []string) {
+	history, hashes := historytest.Linear(t, versions...)
+	head := hashes[len(hashes)-1]
+	history.Heads = []objects.Head{{Name: "HEAD", SHA: head}}
+	resolve := func(rev string) (string, error) {
+		if rev == "HEAD" {
+			return head, nil
+		}
+		if _, ok := history.Commits[rev]; ok {
+			return rev, nil
+		}
+		return "", fmt.Errorf("unknown revision %s", rev)
+	}
+	return &handler{history: history, repoName: "p", resolve: resolve}, hashes
+}
+
+// serve calls handler with request for target and name path parameter, it returns response code and body.
+func serve(h echo.HandlerFunc, target, name string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, target, nil), rec)
+	c.SetParamNames("name")
+	c.SetParamValues(url.QueryEscape(name))
+	if err := h(c); err != nil {
+		rec.Code = http.StatusInternalServerError
+	}
+	return rec
+}
+
+func TestAPIDiff(t *testing.T) {
+	h, hashes := testHandler(t, "func F() { a() }", "func G() {}", "func F() { b() }")
+	tests := []struct {
+		name  string
+		id    string
+		query string
+		code  int
+		left  string
+		right string
+	}{
+		{"unknown name", "p.Unknown", "", http.StatusNotFound, "", ""},
+		{"bad from", "p.F", "from=unknown", http.StatusBadRequest, "", ""},
+		{"bad to", "p.F", "from=" + hashes[0] + "&to=unknown", http.StatusBadRequest, "", ""},
+		{"not present at to", "p.F", "from=" + hashes[0] + "&to=" + hashes[1], http.StatusBadRequest, "", ""},
+		{"bad engine", "p.F", "engine=unknown", http.StatusBadRequest, "", ""},
+		{"deleted version", "p.F", "pos=" + hashes[1], http.StatusOK, "func F() { a() }", ""},
+		{"deleted version lcs", "p.F", "pos=" + hashes[1] + "&engine=lcs", http.StatusOK, "func F() { a() }", ""},
+		{"deleted version tree", "p.F", "pos=" + hashes[1] + "&engine=tree", http.StatusOK, "func F() { a() }", ""},
+		{"revisions", "p.F", "from=" + hashes[0], http.StatusOK, "func F() { a() }", "func F() { b() }"},
+		{"first version", "p.F", "", http.StatusOK, "", "func F() { a() }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h.APIDiff(kindFunctions), "/?"+tt.query, tt.id)
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+			if tt.code != http.StatusOK {
+				var apiErr APIError
+				if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil || apiErr.Error == "" {
+					t.Errorf("body = %s, want error", rec.Body)
+				}
+				return
+			}
+			var result APIDiff
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+			var left string
+			if result.Left != nil {
+				left = result.Left.Text
+			}
+			if left != tt.left || result.Right.Text != tt.right {
+				t.Errorf("diff = %q, %q, want %q, %q", left, result.Right.Text, tt.left, tt.right)
+			}
+			if deleted := tt.right == ""; result.Right.Element.Deleted != deleted {
+				t.Errorf("right deleted = %v, want %v", result.Right.Element.Deleted, deleted)
+			}
+			if tt.right == "" && tt.left != "" {
+				removed := []APIColorChange{{Color: "removed", Pos: 0, End: len(tt.left) - 1}}
+				if !reflect.DeepEqual(result.Left.Coloring, removed) || len(result.Right.Coloring) != 0 {
+					t.Errorf("coloring = %v, %v, want %v, none", result.Left.Coloring, result.Right.Coloring, removed)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"fmt"
+	"go/token"
 	"html/template"
 	"io"
 	"net/http"
@@ -9,18 +11,24 @@ import (
 	"path"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo"
 	"github.com/sirupsen/logrus"
 	"github.com/wookesh/gohist/diff"
 	"github.com/wookesh/gohist/objects"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
 type handler struct {
 	history  *objects.History
 	repoName string
+	resolve  RevisionResolver
 }
 
+// RevisionResolver returns hash of commit given revision points to.
+type RevisionResolver func(rev string) (string, error)
+
 type Template struct {
 	templates *template.Template
 }
@@ -30,15 +38,21 @@ func (t *Template) Render(w io.Writer, name string, data interface{}, c echo.Con
 }
 
 type Link struct {
-	Name    string
-	First   string
-	Len     int
-	Total   int
-	Deleted bool
+	Name      string
+	First     string
+	Len       int
+	Total     int
+	Deleted   bool
+	Generated bool
+	Test      objects.TestKind
 }
 
 type ListViewData struct {
 	RepoName   string
+	Kind       string
+	Generated  bool
+	Change     string
+	Categories []diff.Category
 	Links      Links
 	Stats      map[string]interface{}
 	ChartsData map[string]objects.ChartData
@@ -49,22 +63,95 @@ func (l Links) Len() int           { return len(l) }
 func (l Links) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
 func (l Links) Less(i, j int) bool { return l[i].Name < l[j].Name }
 
+const (
+	kindFunctions = "functions"
+	kindTypes     = "types"
+	kindVariables = "variables"
+)
+
+func (h *handler) histories(kind string) map[string]*objects.FunctionHistory {
+	switch kind {
+	case kindTypes:
+		histories := make(map[string]*objects.FunctionHistory, len(h.history.Types))
+		for name, th := range h.history.Types {
+			histories[name] = th.FunctionHistory
+		}
+		return histories
+	case kindVariables:
+		histories := make(map[string]*objects.FunctionHistory, len(h.history.Variables))
+		for name, vh := range h.history.Variables {
+			histories[name] = vh.FunctionHistory
+		}
+		return histories
+	default:
+		return h.history.Data
+	}
+}
+
+func (h *handler) lookup(kind, name string) *objects.FunctionHistory {
+	switch kind {
+	case kindTypes:
+		if th, ok := h.history.Types[name]; ok {
+			return th.FunctionHistory
+		}
+		return nil
+	case kindVariables:
+		if vh, ok := h.history.Variables[name]; ok {
+			return vh.FunctionHistory
+		}
+		return nil
+	default:
+		return h.history.Data[name]
+	}
+}
+
 func (h *handler) List(c echo.Context) error {
 	onlyChangedStr := c.QueryParam("only_changed")
 	onlyChanged, err := strconv.ParseBool(onlyChangedStr)
 	if err != nil {
 		onlyChanged = false
 	}
-	listData := &ListViewData{RepoName: h.repoName, Stats: h.history.Stats(), ChartsData: h.history.ChartsData()}
-	for fName, fHistory := range h.history.Data {
+	kind := c.QueryParam("kind")
+	if kind != kindTypes && kind != kindVariables {
+		kind = kindFunctions
+	}
+	withGenerated, err := strconv.ParseBool(c.QueryParam("generated"))
+	if err != nil {
+		withGenerated = false
+	}
+	var category diff.Category
+	change := c.QueryParam("change")
+	if change != "" {
+		if category, err = diff.ParseCategory(change); err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+	}
+	listData := &ListViewData{
+		RepoName:   h.repoName,
+		Kind:       kind,
+		Generated:  withGenerated,
+		Change:     change,
+		Categories: h.history.ChangeCategories(),
+		Stats:      h.history.Stats(withGenerated),
+		ChartsData: h.history.ChartsData(withGenerated),
+	}
+	for fName, fHistory := range h.histories(kind) {
+		if fHistory.Generated && !withGenerated {
+			continue
+		}
+		if category != 0 && !fHistory.HasChange(category) {
+			continue
+		}
 		if !onlyChanged || (onlyChanged && (len(fHistory.Elements) > 1 || fHistory.LifeTime == 1)) {
 			listData.Links = append(listData.Links,
 				Link{
-					Name:    fName,
-					First:   fHistory.First.Commit.Hash.String(),
-					Len:     fHistory.VersionsCount(),
-					Total:   fHistory.LifeTime,
-					Deleted: fHistory.Deleted,
+					Name:      fName,
+					First:     fHistory.First.Commit.Hash.String(),
+					Len:       fHistory.VersionsCount(),
+					Total:     fHistory.LifeTime,
+					Deleted:   fHistory.Deleted,
+					Generated: fHistory.Generated,
+					Test:      fHistory.Test,
 				})
 		}
 	}
@@ -75,64 +162,416 @@ func (h *handler) List(c echo.Context) error {
 type DiffView struct {
 	Name        string
 	History     *objects.FunctionHistory
+	Left, Right *objects.HistoryElement
 	LeftDiff    diff.Coloring
 	RightDiff   diff.Coloring
 	First, Last string
+	// From and To are compared revisions, empty when element is compared with its parent
+	From, To string
+	// TestsChanged are tests changed together with shown version, Untested counts versions changed without tests
+	TestsChanged map[*objects.FunctionHistory]bool
+	Untested     int
+	// Category is kind of change between compared versions
+	Category diff.Category
+	// LeftDoc and RightDoc are doc comments present in compared commits, Docs is the whole doc history
+	LeftDocAt, RightDocAt     string
+	LeftDoc, RightDoc         *objects.DocVersion
+	LeftDocDiff, RightDocDiff diff.Coloring
+	Docs                      []DocChange
+}
+
+// docCommits returns commits docs are compared at, compared revisions or commits of compared versions.
+func (h *handler) docCommits(left, right *objects.HistoryElement, from, to string) (string, string) {
+	var leftSHA, rightSHA string
+	if left != nil {
+		leftSHA = left.Commit.Hash.String()
+	}
+	rightSHA = right.Commit.Hash.String()
+	if h.resolve == nil || (from == "" && to == "") {
+		return leftSHA, rightSHA
+	}
+	if sha, err := h.resolve(from); err == nil && left != nil {
+		leftSHA = sha
+	}
+	if sha, err := h.resolve(to); err == nil {
+		rightSHA = sha
+	}
+	return leftSHA, rightSHA
+}
+
+// DocChange is doc version with coloring of its change from parent version.
+type DocChange struct {
+	*objects.DocVersion
+	Coloring diff.Coloring
+}
+
+// diffEngine returns engine selected by engine query param, lcs=yes selects LCS when engine is not set.
+func diffEngine(c echo.Context) (diff.Engine, error) {
+	if c.QueryParam("engine") == "" && c.QueryParam("lcs") == "yes" {
+		return diff.EngineLCS, nil
+	}
+	return diff.ParseEngine(c.QueryParam("engine"))
 }
 
 func (h *handler) Get(c echo.Context) error {
+	return h.get(c, kindFunctions)
+}
+
+func (h *handler) GetType(c echo.Context) error {
+	return h.get(c, kindTypes)
+}
+
+func (h *handler) GetVariable(c echo.Context) error {
+	return h.get(c, kindVariables)
+}
+
+func (h *handler) get(c echo.Context, kind string) error {
 	funcName := c.Param("name")
 	funcName, err := url.QueryUnescape(funcName)
 	if err != nil {
 		return c.HTML(http.StatusNotFound, "NOT FOUND")
 	}
-	f, ok := h.history.Data[funcName]
-	if !ok {
+	f := h.lookup(kind, funcName)
+	if f == nil {
 		return c.HTML(http.StatusNotFound, "NOT FOUND")
 	}
 
-	pos := c.QueryParam("pos")
-	cmp := c.QueryParam("cmp")
-	useLCS := c.QueryParam("lcs")
+	var pos, cmp string
+	var left, right *objects.HistoryElement
+	from, to := c.QueryParam("from"), c.QueryParam("to")
+	if from != "" || to != "" {
+		to = h.orMainHead(to)
+		left, right, err = h.compareRevisions(f, from, to)
+		if err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		pos = right.Commit.Hash.String()
+	} else {
+		pos, cmp = selectElements(f, c.QueryParam("pos"), c.QueryParam("cmp"))
+		right = f.Elements[pos]
+		left = right.Compared(cmp)
+	}
+	engine, err := diffEngine(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	alpha := c.QueryParam("alpha")
+	leftDiff, rightDiff := right.Diff(left, engine, alpha == "yes")
+	diffView := &DiffView{
+		Name:      funcName,
+		History:   f,
+		Left:      left,
+		Right:     right,
+		LeftDiff:  leftDiff,
+		RightDiff: rightDiff,
+		Last:      f.Last.Commit.Hash.String(),
+		First:     f.First.Commit.Hash.String(),
+		From:      from,
+		To:        to,
+
+		TestsChanged: make(map[*objects.FunctionHistory]bool),
+		Untested:     len(f.UntestedChanges()),
+	}
+	if left != nil {
+		diffView.Category = right.Classify(left)
+	}
+	if len(f.Docs) > 0 {
+		diffView.LeftDocAt, diffView.RightDocAt = h.docCommits(left, right, from, to)
+		diffView.RightDoc = f.DocAt(diffView.RightDocAt)
+		diffView.LeftDoc = f.DocAt(diffView.LeftDocAt)
+		if diffView.RightDoc != nil && diffView.LeftDoc != diffView.RightDoc {
+			diffView.LeftDocDiff, diffView.RightDocDiff = diffView.RightDoc.Diff(diffView.LeftDoc)
+		}
+		for _, version := range f.SortedDocs() {
+			_, coloring := version.Diff(version.Parent)
+			diffView.Docs = append(diffView.Docs, DocChange{DocVersion: version, Coloring: coloring})
+		}
+	}
+	for _, test := range f.TestsChanged(right) {
+		diffView.TestsChanged[test] = true
+	}
+	data := map[string]interface{}{"pos": pos, "diffView": diffView, "cmp": cmp, "engine": engine.String(), "alpha": alpha, "kind": kind}
+	if c.QueryParam("blame") == "yes" {
+		data["blame"] = blameLines(f, right)
+	}
+	if len(h.history.Heads) > 1 {
+		data["branches"] = branchStates(h.history.Heads, f, right)
+	}
+	return c.Render(http.StatusOK, "diff.html", data)
+}
+
+// BranchState is version of function on analyzed branch, Element is nil when branch never contained it.
+type BranchState struct {
+	Head    objects.Head
+	Element *objects.HistoryElement
+	Current bool
+}
+
+func branchStates(heads []objects.Head, f *objects.FunctionHistory, current *objects.HistoryElement) (states []BranchState) {
+	for _, head := range heads {
+		elem := f.At(head.SHA)
+		states = append(states, BranchState{Head: head, Element: elem, Current: elem == current})
+	}
+	return states
+}
+
+// CommitChange is change of one declaration with colorings of its compared versions.
+type CommitChange struct {
+	objects.Change
+	LeftDiff, RightDiff diff.Coloring
+	Link                string
+	// Origin is set when compared version belongs to history the declaration was renamed or moved from
+	Origin *objects.FunctionHistory
+}
+
+type CommitView struct {
+	RepoName          string
+	Commit            *object.Commit
+	Parents, Children []string
+	Changes           []CommitChange
+	Engine            string
+	Alpha             string
+}
+
+func (h *handler) Commit(c echo.Context) error {
+	commit := h.commit(c.Param("sha"))
+	if commit == nil {
+		return c.HTML(http.StatusNotFound, "NOT FOUND")
+	}
+	sha := commit.Hash.String()
+	engine, err := diffEngine(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	view := &CommitView{RepoName: h.repoName, Commit: commit, Engine: engine.String(), Alpha: c.QueryParam("alpha")}
+	view.Parents, view.Children = h.commitNeighbours(commit)
+	for _, change := range h.history.CommitChanges(sha) {
+		left, right := change.Element.Diff(change.Parent, engine, view.Alpha == "yes")
+		commitChange := CommitChange{
+			Change:    change,
+			LeftDiff:  left,
+			RightDiff: right,
+			Link:      kindPath(change.Kind) + url.QueryEscape(change.History.ID) + "/?pos=" + sha,
+		}
+		if change.Parent != nil {
+			if owner := historyOf(change.History, change.Parent); owner != change.History {
+				commitChange.Origin = owner
+			}
+		}
+		view.Changes = append(view.Changes, commitChange)
+	}
+	return c.Render(http.StatusOK, "commit.html", view)
+}
+
+type HotspotsView struct {
+	RepoName  string
+	Package   string
+	Sort      string
+	Generated bool
+	SortKeys  []string
+	Packages  []string
+	Hotspots  []objects.Hotspot
+}
+
+func (h *handler) Hotspots(c echo.Context) error {
+	withGenerated, err := strconv.ParseBool(c.QueryParam("generated"))
+	if err != nil {
+		withGenerated = false
+	}
+	view := &HotspotsView{
+		RepoName:  h.repoName,
+		Package:   c.QueryParam("package"),
+		Sort:      c.QueryParam("sort"),
+		Generated: withGenerated,
+		SortKeys:  objects.HotspotSortKeys,
+	}
+	if view.Sort == "" {
+		view.Sort = "score"
+	}
+	hotspots := h.history.Hotspots("", withGenerated)
+	packages := make(map[string]bool)
+	for _, hotspot := range hotspots {
+		packages[hotspot.Package] = true
+	}
+	for pkg := range packages {
+		view.Packages = append(view.Packages, pkg)
+	}
+	sort.Strings(view.Packages)
+	view.Hotspots = objects.FilterHotspots(hotspots, view.Package)
+	if err := objects.SortHotspots(view.Hotspots, view.Sort); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	return c.Render(http.StatusOK, "hotspots.html", view)
+}
+
+type OwnershipView struct {
+	RepoName  string
+	Package   string
+	Left      string
+	Generated bool
+	*objects.OwnershipReport
+}
+
+func (h *handler) Ownership(c echo.Context) error {
+	withGenerated, err := strconv.ParseBool(c.QueryParam("generated"))
+	if err != nil {
+		withGenerated = false
+	}
+	view := &OwnershipView{
+		RepoName:  h.repoName,
+		Package:   c.QueryParam("package"),
+		Left:      c.QueryParam("left"),
+		Generated: withGenerated,
+	}
+	view.OwnershipReport = h.history.Ownership(view.Package, strings.Split(view.Left, ","), withGenerated)
+	return c.Render(http.StatusOK, "ownership.html", view)
+}
+
+// commit returns analyzed commit rev points to, it is nil when revision is unknown or was not analyzed.
+func (h *handler) commit(rev string) *object.Commit {
+	if commit, ok := h.history.Commits[rev]; ok {
+		return commit
+	}
+	if h.resolve == nil {
+		return nil
+	}
+	sha, err := h.resolve(rev)
+	if err != nil {
+		return nil
+	}
+	return h.history.Commits[sha]
+}
+
+// commitNeighbours returns analyzed parents and children of commit.
+func (h *handler) commitNeighbours(commit *object.Commit) (parents, children []string) {
+	for _, parent := range commit.ParentHashes {
+		if _, ok := h.history.Commits[parent.String()]; ok {
+			parents = append(parents, parent.String())
+		}
+	}
+	for sha, other := range h.history.Commits {
+		for _, parent := range other.ParentHashes {
+			if parent == commit.Hash {
+				children = append(children, sha)
+			}
+		}
+	}
+	sort.Strings(children)
+	return parents, children
+}
+
+// kindPath returns ui path prefix of declarations of given change kind.
+func kindPath(kind string) string {
+	switch kind {
+	case "type":
+		return "/" + kindTypes + "/"
+	case "variable":
+		return "/" + kindVariables + "/"
+	default:
+		return "/"
+	}
+}
+
+// compareRevisions returns versions of f live at from and to revisions, left version is nil when function
+// did not exist at from.
+func (h *handler) compareRevisions(f *objects.FunctionHistory, from, to string) (left, right *objects.HistoryElement, err error) {
+	if left, err = h.liveAt(f, from); err != nil {
+		return nil, nil, err
+	}
+	if right, err = h.liveAt(f, to); err != nil {
+		return nil, nil, err
+	}
+	if right == nil {
+		return nil, nil, fmt.Errorf("%s is not present at %s", f.ID, to)
+	}
+	return left, right, nil
+}
+
+// orMainHead returns rev or the main analyzed revision when rev is empty.
+func (h *handler) orMainHead(rev string) string {
+	if rev == "" && len(h.history.Heads) > 0 {
+		return h.history.Heads[0].Name
+	}
+	return rev
+}
+
+// liveAt resolves revision and returns version of f present in it, deleted versions are reported as nil.
+func (h *handler) liveAt(f *objects.FunctionHistory, rev string) (*objects.HistoryElement, error) {
+	if rev == "" {
+		return nil, nil
+	}
+	if h.resolve == nil {
+		return nil, fmt.Errorf("revisions can not be resolved")
+	}
+	sha, err := h.resolve(rev)
+	if err != nil {
+		return nil, err
+	}
+	elem := f.At(sha)
+	if elem == nil || elem.Decl == nil {
+		return nil, nil
+	}
+	return elem, nil
+}
+
+// selectElements validates requested element and the one it is compared with, falling back to defaults.
+func selectElements(f *objects.FunctionHistory, pos, cmp string) (string, string) {
 	if _, ok := f.Elements[pos]; pos == "" || !ok {
 		pos = f.First.Commit.Hash.String()
 	}
 	element := f.Elements[pos]
-	if _, ok := element.Parent[cmp]; cmp == "" || !ok {
+	if cmp == "" || element.Compared(cmp) == nil {
 		for sha := range element.Parent { // get random
 			cmp = sha
 			break
 		}
+		if len(element.Parent) == 0 && element.Origin != nil {
+			cmp = element.Origin.Commit.Hash.String()
+		}
 	}
-	comparedElement := f.Elements[cmp]
-	var left, right diff.Coloring
-	switch pos {
-	case f.First.Commit.Hash.String():
-		right = diff.Diff(nil, element.Func, diff.ModeNew)
-	default:
-		if useLCS == "yes" {
-			left = diff.LCS(comparedElement.Text, element.Text, comparedElement.Offset, diff.ModeOld)
-			right = diff.LCS(comparedElement.Text, element.Text, element.Offset, diff.ModeNew)
+	return pos, cmp
+}
 
-		} else {
-			left = diff.Diff(comparedElement.Func, element.Func, diff.ModeOld)
-			right = diff.Diff(element.Func, comparedElement.Func, diff.ModeNew)
+type BlameLine struct {
+	Text    string
+	Element *objects.HistoryElement
+	Link    string
+	First   bool
+}
+
+func blameLines(f *objects.FunctionHistory, elem *objects.HistoryElement) (result []BlameLine) {
+	if elem.Decl == nil {
+		return nil
+	}
+	lines := elem.Blame().Lines()
+	var prev *objects.HistoryElement
+	for i, text := range strings.Split(elem.Text, "\n") {
+		line := BlameLine{Text: text, Element: lines[i]}
+		if line.Element != nil {
+			line.Link = "?pos=" + line.Element.Commit.Hash.String()
+			if owner := historyOf(f, line.Element); owner != f {
+				line.Link = "/" + url.QueryEscape(owner.ID) + "/" + line.Link
+			}
+			line.First = line.Element != prev
+			prev = line.Element
 		}
+		result = append(result, line)
 	}
-	diffView := &DiffView{
-		Name:      funcName,
-		History:   f,
-		LeftDiff:  left,
-		RightDiff: right,
-		Last:      f.Last.Commit.Hash.String(),
-		First:     f.First.Commit.Hash.String(),
+	return result
+}
+
+// historyOf finds history containing elem among f and histories it was renamed or moved from.
+func historyOf(f *objects.FunctionHistory, elem *objects.HistoryElement) *objects.FunctionHistory {
+	for h := f; h != nil; h = h.Origin {
+		if h.Elements[elem.Commit.Hash.String()] == elem {
+			return h
+		}
 	}
-	data := map[string]interface{}{"pos": pos, "diffView": diffView, "cmp": cmp, "lcs": useLCS}
-	return c.Render(http.StatusOK, "diff.html", data)
+	return f
 }
 
-func Run(history *objects.History, repoName, port string) {
-	handler := handler{history: history, repoName: repoName}
+func Run(history *objects.History, repoName, port string, resolve RevisionResolver) {
+	handler := handler{history: history, repoName: repoName, resolve: resolve}
 
 	funcMap := template.FuncMap{
 		"next": func(i int64) int64 {
@@ -158,6 +597,9 @@ func Run(history *objects.History, repoName, port string) {
 				return "danger"
 			}
 		},
+		"percent": func(f float64) string {
+			return fmt.Sprintf("%.0f%%", 100*f)
+		},
 		"escape": func(s string) string {
 			return url.QueryEscape(s)
 		},
@@ -174,8 +616,29 @@ func Run(history *objects.History, repoName, port string) {
 
 	e.GET("/", handler.List)
 	e.GET("/:name/", handler.Get)
+	e.GET("/types/:name/", handler.GetType)
+	e.GET("/variables/:name/", handler.GetVariable)
+	e.GET("/commit/:sha/", handler.Commit)
+	e.GET("/hotspots/", handler.Hotspots)
+	e.GET("/ownership/", handler.Ownership)
 	e.Static("/static", path.Join(rootPath, "ui/static"))
 
+	api := e.Group("/api/v1")
+	api.GET("/commits/:sha", handler.APICommitChanges)
+	api.GET("/breaking", handler.APIBreaking)
+	api.GET("/hotspots", handler.APIHotspots)
+	api.GET("/ownership", handler.APIOwnership)
+	for _, kind := range []string{kindFunctions, kindTypes, kindVariables} {
+		api.GET("/"+kind, handler.APIList(kind))
+		api.GET("/"+kind+"/:name", handler.APIGet(kind))
+		api.GET("/"+kind+"/:name/versions", handler.APIVersions(kind))
+		api.GET("/"+kind+"/:name/diff", handler.APIDiff(kind))
+		api.GET("/"+kind+"/:name/script", handler.APIScript(kind))
+		api.GET("/"+kind+"/:name/blame", handler.APIBlame(kind))
+		api.GET("/"+kind+"/:name/branches", handler.APIBranches(kind))
+		api.GET("/"+kind+"/:name/docs", handler.APIDocs(kind))
+	}
+
 	logrus.Infoln("GoHist:", "started web server")
 
 	if err := e.Start("0.0.0.0:" + port); err != nil {
@@ -190,26 +653,31 @@ func color(s string, coloring diff.Coloring, offset int) template.HTML {
 	logrus.Debugln("color:", coloring, offset)
 	current := 0
 	var hasColoring bool
-	var result string
+	var result, closing string
+	anchored := make(map[token.Pos]bool)
 	logrus.Debugln("color:", "next coloring:", current, coloring[current])
 	for i := 0; i < len(s); i++ {
 		if current < len(coloring) {
 			if !hasColoring && int(coloring[current].Pos) <= i+offset {
 				logrus.Debugln("color:", "changing color:", toColor(coloring[current].Color), i+offset)
 				hasColoring = true
-				result += `<span style="color: ` + toColor(coloring[current].Color) + `;">`
+				var opening string
+				opening, closing = colorTags(coloring[current], anchored)
+				result += opening
 			}
 
 			if hasColoring && int(coloring[current].End) < i+offset {
 				logrus.Debugln("color:", "removing color:", i+offset)
-				result += `</span>`
+				result += closing
 				if current < len(coloring) {
 					current++
 					logrus.Debugln("color:", "next coloring:", current)
 				}
 				if current < len(coloring) && int(coloring[current].Pos) <= i+offset {
 					logrus.Debugln("color:", "changing color:", toColor(coloring[current].Color), i+offset)
-					result += `<span style="color: ` + toColor(coloring[current].Color) + `;">`
+					var opening string
+					opening, closing = colorTags(coloring[current], anchored)
+					result += opening
 				} else {
 					hasColoring = false
 				}
@@ -222,11 +690,32 @@ func color(s string, coloring diff.Coloring, offset int) template.HTML {
 		}
 	}
 	if hasColoring {
-		result += `</span>`
+		result += closing
 	}
 	return template.HTML(result)
 }
 
+// colorTags returns tags around colored range, moved statements link to their location in the other version and
+// the first range of every move is its anchor.
+func colorTags(change diff.ColorChange, anchored map[token.Pos]bool) (string, string) {
+	style := `style="color: ` + toColor(change.Color) + `;"`
+	if change.Move == nil {
+		return `<span ` + style + `>`, `</span>`
+	}
+	side, other := "old", "new"
+	if change.Move.Mode == diff.ModeNew {
+		side, other = other, side
+	}
+	key := change.Move.Key()
+	var id string
+	if !anchored[key] {
+		anchored[key] = true
+		id = fmt.Sprintf(` id="moved-%s-%d"`, side, key)
+	}
+	return fmt.Sprintf(`<a%s href="#moved-%s-%d" title="moved, go to %s location" %s>`, id, other, key, other, style),
+		`</a>`
+}
+
 func toColor(c diff.Color) string {
 	switch c {
 	case diff.ColorSame:
@@ -237,6 +726,8 @@ func toColor(c diff.Color) string {
 		return "red"
 	case diff.ColorSimilar:
 		return "lightblue"
+	case diff.ColorMoved:
+		return "orchid"
 	default:
 		return "white"
 	}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLiveAt(t *testing.T) {
+	h, hashes := testHandler(t, "func F() { a() }", "func G() {}", "func F() { b() }")
+	f := h.history.Data["p.F"]
+	tests := []struct {
+		name    string
+		rev     string
+		commit  string
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"live", hashes[0], hashes[0], false},
+		{"head", "HEAD", hashes[2], false},
+		{"deleted", hashes[1], "", false},
+		{"unknown", "unknown", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elem, err := h.liveAt(f, tt.rev)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("liveAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var commit string
+			if elem != nil {
+				commit = elem.Commit.Hash.String()
+			}
+			if commit != tt.commit {
+				t.Errorf("liveAt() = version of %q, want %q", commit, tt.commit)
+			}
+		})
+	}
+	h.resolve = nil
+	if _, err := h.liveAt(f, "HEAD"); err == nil {
+		t.Error("liveAt() without resolver did not fail")
+	}
+}
+
+func TestCompareRevisions(t *testing.T) {
+	h, hashes := testHandler(t, "func F() { a() }", "func G() {}", "func F() { b() }")
+	f := h.history.Data["p.F"]
+	tests := []struct {
+		name        string
+		from, to    string
+		left, right string
+		err         string
+	}{
+		{"changed", hashes[0], hashes[2], hashes[0], hashes[2], ""},
+		{"without from", "", hashes[2], "", hashes[2], ""},
+		{"from deleted", hashes[1], hashes[2], "", hashes[2], ""},
+		{"not present at to", hashes[0], hashes[1], "", "", "p.F is not present at " + hashes[1]},
+		{"unknown from", "unknown", hashes[2], "", "", "unknown revision unknown"},
+		{"unknown to", hashes[0], "unknown", "", "", "unknown revision unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, right, err := h.compareRevisions(f, tt.from, tt.to)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("compareRevisions() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var leftCommit string
+			if left != nil {
+				leftCommit = left.Commit.Hash.String()
+			}
+			if leftCommit != tt.left || right.Commit.Hash.String() != tt.right {
+				t.Errorf("compareRevisions() = %q, %q, want %q, %q", leftCommit, right.Commit.Hash, tt.left, tt.right)
+			}
+		})
+	}
+}
@@ -13,3 +13,10 @@ func IntMin(a, b int) int {
 	}
 	return b
 }
+
+func IntAbs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}